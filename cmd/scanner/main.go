@@ -1,34 +1,164 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"sort"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/digimosa/ai-gdpr-scan/internal/ai"
 	"github.com/digimosa/ai-gdpr-scan/internal/config"
+	"github.com/digimosa/ai-gdpr-scan/internal/eval"
+	"github.com/digimosa/ai-gdpr-scan/internal/extractor/detectors"
 	"github.com/digimosa/ai-gdpr-scan/internal/scanner"
 	"github.com/digimosa/ai-gdpr-scan/internal/server"
 	"github.com/digimosa/ai-gdpr-scan/internal/storage"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "eval" {
+		if err := runEval(os.Args[2:]); err != nil {
+			fmt.Printf("[ERROR] %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Parse CLI flags
 	rootPath := flag.String("path", ".", "Root directory to scan")
 	scan := flag.Bool("scan", false, "Execute scan immediately (CLI mode)")
+	include := flag.String("include", "", "Comma-separated gitignore-style patterns; only matching files are scanned (e.g. '*.pdf,reports/')")
+	exclude := flag.String("exclude", "", "Comma-separated gitignore-style patterns to skip (e.g. 'node_modules/,*.bak'); see also .gdprignore files")
+	minSize := flag.String("min-size", "", "Skip files smaller than this (e.g. 1KB)")
+	maxSize := flag.String("max-size", "", "Skip files larger than this (e.g. 100MB)")
+	maxAge := flag.Duration("max-age", 0, "Skip files modified longer ago than this (e.g. 8760h)")
+	minAge := flag.Duration("min-age", 0, "Skip files modified more recently than this (e.g. 1h)")
+	skipHidden := flag.Bool("skip-hidden", false, "Skip files and directories whose name starts with a dot")
+	followSymlinks := flag.Bool("follow-symlinks", false, "Walk symlinked directories, skipping symlink loops (symlinked files are always scanned)")
+	incremental := flag.Bool("incremental", false, "Only extract new and modified files; findings of unchanged files are carried forward from their last scan")
+	resume := flag.Uint("resume", 0, "Continue an interrupted scan with this ID, skipping the files it already scanned (implies -scan)")
 	workers := flag.Int("workers", 0, "Number of concurrent workers (default: auto)")
 	verbose := flag.Bool("verbose", false, "Enable verbose logging")
 	serve := flag.Bool("serve", false, "Start a web server to review results and manage whitelist after scan")
 	port := flag.String("port", "8080", "Port for the web server")
+	phoneRegion := flag.String("phone-region", "", "Default region for national phone number formats (e.g. DE, AT, GB)")
+	aiProvider := flag.String("ai-provider", "", "AI backend: ollama, openai (vLLM, LM Studio, LocalAI) or llamacpp")
+	aiURL := flag.String("ai-url", "", "Base URL of the AI backend (e.g. http://localhost:11434)")
+	aiModel := flag.String("ai-model", "", "Model name sent to the AI backend")
+	pseudonymize := flag.Bool("pseudonymize", false, "Replace detected values with placeholders before sending text to the AI backend")
+	aiLargeModel := flag.String("ai-large-model", "", "Larger model for the types in -ai-large-types and for uncertain answers of -ai-model")
+	aiLargeTypes := flag.String("ai-large-types", "", "Comma-separated finding types reviewed by -ai-large-model (default: Name,Sensitive)")
+	aiFallbackModels := flag.String("ai-fallback-models", "", "Comma-separated models to use when -ai-model is missing or does not answer (default: any installed model)")
+	aiModelCosts := flag.String("ai-model-costs", "", "Comma-separated prices per 1000 tokens for the cost figures in the summary (e.g. gpt-4o-mini=0.0006)")
+	aiSkipTypes := flag.String("ai-skip-types", "", "Comma-separated finding types reported without AI review (e.g. IBAN,CreditCard)")
+	aiEmbedFilter := flag.Bool("ai-embed-filter", false, "Drop candidates resembling reviewed false positives before the AI review, using an embedding model")
+	aiEmbedModel := flag.String("ai-embed-model", "", "Embedding model of -ai-embed-filter (default: nomic-embed-text)")
+	aiClassify := flag.Bool("ai-classify", false, "Ask the AI backend for the document category when keyword profiles are unsure")
+	shutdownGrace := flag.Duration("shutdown-grace", 0, "Time files being scanned get to finish after Ctrl+C before they are aborted (default: 30s)")
+	keywordFindings := flag.String("keyword-findings", "", "Comma-separated keyword types reported on their own (e.g. Sensitive,OfficialID)")
 	flag.Parse()
 
 	// Setup configuration
 	cfg := config.DefaultConfig()
 	cfg.RootPath = *rootPath
 	cfg.Verbose = *verbose
+	cfg.Incremental = *incremental
+	if *include != "" {
+		cfg.Include = strings.Split(*include, ",")
+	}
+	if *exclude != "" {
+		cfg.Exclude = strings.Split(*exclude, ",")
+	}
+	for _, limit := range []struct {
+		flag  string
+		value string
+		size  *int64
+	}{{"-min-size", *minSize, &cfg.MinSize}, {"-max-size", *maxSize, &cfg.MaxSize}} {
+		if limit.value == "" {
+			continue
+		}
+		size, err := config.ParseSize(limit.value)
+		if err != nil {
+			fmt.Printf("[ERROR] %s: %v\n", limit.flag, err)
+			return
+		}
+		*limit.size = size
+	}
+	cfg.MaxAge = *maxAge
+	cfg.MinAge = *minAge
+	cfg.SkipHidden = *skipHidden
+	cfg.FollowSymlinks = *followSymlinks
 	if *workers > 0 {
 		cfg.Workers = *workers
 	}
+	if *phoneRegion != "" {
+		regions := detectors.SupportedPhoneRegions()
+		if !slices.Contains(regions, strings.ToUpper(*phoneRegion)) {
+			fmt.Printf("[ERROR] -phone-region: unsupported region %q (supported: %s)\n", *phoneRegion, strings.Join(regions, ", "))
+			os.Exit(1)
+		}
+		cfg.PhoneRegion = *phoneRegion
+	}
+	if *aiProvider != "" {
+		cfg.AIProvider = *aiProvider
+	}
+	if *aiURL != "" {
+		cfg.AIURL = *aiURL
+	}
+	if *aiModel != "" {
+		cfg.AIModel = *aiModel
+	}
+	cfg.AIPseudonymize = *pseudonymize
+	cfg.AIClassify = *aiClassify
+	cfg.AIEmbedFilter = *aiEmbedFilter
+	if *aiEmbedModel != "" {
+		cfg.AIEmbedModel = *aiEmbedModel
+	}
+	if *aiLargeModel != "" {
+		cfg.AILargeModel = *aiLargeModel
+	}
+	if *aiLargeTypes != "" {
+		types, err := ai.ParseTypes(strings.Split(*aiLargeTypes, ","))
+		if err != nil {
+			fmt.Printf("[ERROR] -ai-large-types: %v\n", err)
+			os.Exit(1)
+		}
+		cfg.AILargeTypes = types
+	}
+	if *aiFallbackModels != "" {
+		cfg.AIFallbackModels = strings.Split(*aiFallbackModels, ",")
+	}
+	if *aiModelCosts != "" {
+		costs, err := ai.ParseModelCosts(strings.Split(*aiModelCosts, ","))
+		if err != nil {
+			fmt.Printf("[ERROR] -ai-model-costs: %v\n", err)
+			os.Exit(1)
+		}
+		cfg.AIModelCosts = costs
+	}
+	if *aiSkipTypes != "" {
+		types, err := ai.ParseTypes(strings.Split(*aiSkipTypes, ","))
+		if err != nil {
+			fmt.Printf("[ERROR] -ai-skip-types: %v\n", err)
+			os.Exit(1)
+		}
+		cfg.AISkipTypes = types
+	}
+	// Keep API keys out of the process list
+	cfg.AIAPIKey = os.Getenv("GDPR_SCAN_AI_API_KEY")
+	cfg.AdminToken = os.Getenv("GDPR_SCAN_ADMIN_TOKEN")
+	if *keywordFindings != "" {
+		cfg.KeywordFindings = strings.Split(*keywordFindings, ",")
+	}
+	if *shutdownGrace > 0 {
+		cfg.ShutdownGrace = *shutdownGrace
+	}
 
 	// Initialize Storage
 	fmt.Printf("Initializing database at: %s\n", cfg.DBPath)
@@ -37,38 +167,104 @@ func main() {
 		return
 	}
 
+	// Scans whose process died stay "Running" and can be continued
+	var resumeScan *storage.ScanModel
+	if *resume != 0 {
+		sc, err := storage.ResumeScan(*resume)
+		if err != nil {
+			fmt.Printf("[ERROR] %v\n", err)
+			return
+		}
+		resumeScan = sc
+		cfg.RootPath = sc.RootPath
+		*scan = true
+	} else if interrupted, err := storage.InterruptedScans(); err == nil {
+		for _, sc := range interrupted {
+			files, _ := storage.CountCheckpoints(sc.ID)
+			fmt.Printf("[RESUME] Scan %d of %s (started %s) did not finish, %d files stored. Continue it with -resume %d\n",
+				sc.ID, sc.RootPath, sc.StartTime.Format("2006-01-02 15:04"), files, sc.ID)
+		}
+	}
+
 	fmt.Printf("Starting GDPR Scan on: %s\n", cfg.RootPath)
 	fmt.Printf("Workers: %d\n", cfg.Workers)
-	fmt.Printf("Ollama Model: %s\n", cfg.OllamaModel)
-
-	// Check Ollama connection
-	fmt.Print("Checking Ollama connection... ")
-	aiClient := ai.NewClient(cfg)
-	if err := aiClient.Ping(); err != nil {
-		fmt.Printf("FAILED\n[ERROR] Could not connect to Ollama: %v\n", err)
-		fmt.Println("Please ensure Ollama is running and accessible.")
-		// We might want to exit here or continue with warning
-		// Given the requirements, let's exit to prevent false confidence
-		// os.Exit(1)
-		// BUT user might want to scan anyway without AI.
-		// For now, let's warn heavily and continue but maybe update config to disable AI?
-		// Let's exit as requested "test check" implies a gate.
+	fmt.Printf("AI Backend: %s (%s)\n", cfg.AIProvider, cfg.AIURL)
+	fmt.Printf("AI Model: %s\n", cfg.AIModel)
+	if cfg.AILargeModel != "" {
+		fmt.Printf("AI Large Model: %s (%s, escalating %.1f-%.1f)\n", cfg.AILargeModel, strings.Join(cfg.AILargeTypes, ","), cfg.AIEscalateMin, cfg.AIEscalateMax)
+	}
+	if len(cfg.AISkipTypes) > 0 {
+		fmt.Printf("AI skipped for: %s\n", strings.Join(cfg.AISkipTypes, ","))
+	}
+
+	aiClient, err := ai.NewClient(cfg)
+	if err != nil {
+		fmt.Printf("[ERROR] %v\n", err)
 		return
 	}
-	fmt.Println("OK")
+
+	// Check the AI backend and fall back to another model or regex-only
+	// analysis instead of aborting
+	fmt.Println("Checking AI backend...")
+	readiness := aiClient.Diagnose(context.Background(), cfg)
+	readiness.Write(os.Stdout)
+	readiness.Apply(cfg, aiClient)
 
 	// Initialize scanner
-	s := scanner.NewScanner(cfg)
+	s := scanner.NewScannerWithAI(cfg, aiClient)
+	if resumeScan != nil {
+		if err := s.Resume(resumeScan); err != nil {
+			fmt.Printf("[ERROR] Could not resume scan %d: %v\n", resumeScan.ID, err)
+			return
+		}
+		fmt.Printf("Resuming scan %d: %d files already scanned\n", resumeScan.ID, s.Report.Summary.TotalFilesScanned)
+	}
 
 	// CLI Mode: Scan immediately if requested
 	if *scan {
 		start := time.Now()
 
+		// Ctrl+C or SIGTERM stops the scan and keeps the results so far; a
+		// second signal aborts the files still being scanned
+		signals := make(chan os.Signal, 2)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-signals
+			fmt.Printf("\nStopping scan, waiting up to %s for running files (press Ctrl+C again to abort them)...\n", cfg.ShutdownGrace)
+			s.Stop(cfg.ShutdownGrace)
+			<-signals
+			fmt.Println("\nAborting running files...")
+			s.Stop(0)
+		}()
+
 		// The Start method runs the walker and workers in background
 		s.Start()
 		s.Wait()
+		signal.Stop(signals)
 
-		fmt.Printf("\nScan complete in %s\n", time.Since(start))
+		if s.Cancelled() {
+			fmt.Printf("\nScan cancelled after %s, partial results of %d files\n", time.Since(start), s.Report.Summary.TotalFilesScanned)
+		} else {
+			fmt.Printf("\nScan complete in %s\n", time.Since(start))
+		}
+		if skipped := s.Report.Summary.Skipped; len(skipped) > 0 {
+			reasons := make([]string, 0, len(skipped))
+			for reason, n := range skipped {
+				reasons = append(reasons, fmt.Sprintf("%d %s", n, reason))
+			}
+			sort.Strings(reasons)
+			fmt.Printf("Skipped: %s\n", strings.Join(reasons, ", "))
+		}
+		if sum := s.Report.Summary; cfg.Incremental {
+			fmt.Printf("Incremental: %d unchanged, %d modified, %d new, %d removed\n", sum.UnchangedFiles, sum.ModifiedFiles, sum.NewFiles, sum.RemovedFiles)
+		}
+		for _, m := range s.Report.Summary.AI.Models {
+			fmt.Printf("AI model %s: %d requests (%d failed, %d escalated), %d tokens, avg %d ms, max %d ms\n",
+				m.Model, m.Requests, m.Failures, m.Escalations, m.PromptTokens+m.CompletionTokens, m.LatencyAvgMs(), m.LatencyMaxMs)
+		}
+		if stats := s.Report.Summary.AI; stats.EmbedRequests > 0 {
+			fmt.Printf("False-positive filter: %d candidates dropped (%d embedding requests)\n", stats.Filtered, stats.EmbedRequests)
+		}
 
 		// Save Reports
 		jsonFile := "scan_report.json"
@@ -103,3 +299,108 @@ func main() {
 		flag.PrintDefaults()
 	}
 }
+
+// runEval implements "gdpr-scan eval": it scans a labelled corpus and prints
+// precision, recall and F1 per finding type for each pipeline stage
+func runEval(args []string) error {
+	fs := flag.NewFlagSet("eval", flag.ExitOnError)
+	corpus := fs.String("corpus", "", "Corpus directory with the labelled files")
+	manifest := fs.String("manifest", "", "Expected findings (default: <corpus>/manifest.json)")
+	name := fs.String("name", "", "Label of this run in saved results")
+	out := fs.String("out", "", "Save the results as JSON for later comparison")
+	baseline := fs.String("compare", "", "Results of an earlier run to compare with")
+	minScore := fs.Float64("min-score", 0.5, "Confidence a match needs after scoring to count in the scored stage")
+	aiProvider := fs.String("ai-provider", "", "AI backend: ollama, openai or llamacpp")
+	aiURL := fs.String("ai-url", "", "Base URL of the AI backend")
+	aiModel := fs.String("ai-model", "", "Model name sent to the AI backend")
+	fakeModel := fs.Bool("fake-model", false, "Answer with a local fake model that confirms every candidate (deterministic)")
+	noAI := fs.Bool("no-ai", false, "Skip AI analysis; the ai stage then equals a regex-only scan")
+	pseudonymize := fs.Bool("pseudonymize", false, "Replace detected values with placeholders before sending text to the AI backend")
+	db := fs.String("db", "", "Scan database with reviewer feedback, for few-shot examples and -embed-filter")
+	embedFilter := fs.Bool("embed-filter", false, "Drop candidates resembling reviewed false positives (needs -db)")
+	embedModel := fs.String("embed-model", "", "Embedding model of -embed-filter")
+	fs.Parse(args)
+
+	if *corpus == "" {
+		return fmt.Errorf("eval needs -corpus <dir>")
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.RootPath = *corpus
+	cfg.AIAPIKey = os.Getenv("GDPR_SCAN_AI_API_KEY")
+	cfg.AIPseudonymize = *pseudonymize
+	cfg.DisableAI = *noAI
+	cfg.AIEmbedFilter = *embedFilter
+	if *embedModel != "" {
+		cfg.AIEmbedModel = *embedModel
+	}
+	if *aiProvider != "" {
+		cfg.AIProvider = *aiProvider
+	}
+	if *aiURL != "" {
+		cfg.AIURL = *aiURL
+	}
+	if *aiModel != "" {
+		cfg.AIModel = *aiModel
+	}
+	// Verdicts of earlier runs must not hide the effect of a change
+	cfg.AICacheTTL = 0
+	if *db != "" {
+		if err := storage.Init(*db); err != nil {
+			return fmt.Errorf("could not open %s: %v", *db, err)
+		}
+	} else if *embedFilter {
+		return fmt.Errorf("-embed-filter needs -db with reviewed findings")
+	}
+
+	if *fakeModel {
+		srv, err := ai.StartFakeServer(&ai.FakeProvider{Respond: ai.ConfirmCandidates})
+		if err != nil {
+			return err
+		}
+		defer srv.Close()
+		cfg.AIProvider, cfg.AIURL, cfg.AIModel = ai.ProviderOllama, srv.URL, "fake"
+	}
+
+	var aiClient *ai.Client
+	if !cfg.DisableAI {
+		var err error
+		if aiClient, err = ai.NewClient(cfg); err != nil {
+			return err
+		}
+		if err := aiClient.Ping(context.Background()); err != nil {
+			return fmt.Errorf("could not connect to AI backend: %v (use -no-ai or -fake-model)", err)
+		}
+	}
+
+	res, err := eval.Run(cfg, aiClient, eval.Options{
+		CorpusDir:    *corpus,
+		ManifestPath: *manifest,
+		Name:         *name,
+		MinScore:     *minScore,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Evaluated %d files with %s %s (prompt %s)\n", res.Files, res.Provider, res.Model, res.PromptVersion)
+	for _, e := range res.Errors {
+		fmt.Printf("[WARN] %s\n", e)
+	}
+	res.WriteTable(os.Stdout)
+
+	if *baseline != "" {
+		before, err := eval.LoadResult(*baseline)
+		if err != nil {
+			return err
+		}
+		res.WriteComparison(os.Stdout, before)
+	}
+	if *out != "" {
+		if err := res.Save(*out); err != nil {
+			return err
+		}
+		fmt.Printf("\nResults saved to: %s\n", *out)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package ai
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/auditlog"
+)
+
+// Kinds of audited requests
+const (
+	auditChunk    = "chunk"
+	auditClassify = "classify"
+	auditValidate = "validate"
+)
+
+// auditCall identifies the request a model call belongs to
+type auditCall struct {
+	id        string
+	attempt   int
+	kind      string
+	file      string
+	escalated bool
+}
+
+func newAuditCall(kind, file string) auditCall {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return auditCall{id: hex.EncodeToString(id), kind: kind, file: file}
+}
+
+// audit records one model call. The prompt is only hashed and values in the
+// answer are replaced by their hashes; the raw text never reaches the log.
+func (c *Client) audit(call auditCall, req ChatRequest, latency time.Duration, resp *ChatResponse, err error) {
+	e := auditlog.Entry{
+		Time:      time.Now(),
+		RequestID: call.id,
+		Attempt:   call.attempt,
+		Kind:      call.kind,
+		File:      call.file,
+		Provider:  c.Provider.Name(),
+		Model:     req.Model,
+		Escalated: call.escalated,
+		LatencyMs: latency.Milliseconds(),
+		Status:    auditlog.StatusOK,
+	}
+	if err != nil {
+		e.Status, e.Error = auditlog.StatusError, errorClass(err)
+	} else {
+		e.PromptTokens, e.CompletionTokens = resp.PromptTokens, resp.CompletionTokens
+	}
+
+	if c.Verbose {
+		log.Printf("[AI] request %s (%s, attempt %d) %s %s: %s, %d ms, %d+%d tokens %s",
+			e.RequestID, e.Kind, e.Attempt, e.Model, e.File, e.Status, e.LatencyMs, e.PromptTokens, e.CompletionTokens, e.Error)
+	}
+	if c.Audit == nil {
+		return
+	}
+
+	var prompt strings.Builder
+	for _, m := range req.Messages {
+		prompt.WriteString(m.Content)
+	}
+	e.PromptHash = c.Audit.Hash(prompt.String())
+	if err == nil {
+		e.Answer, e.Findings = c.redactAnswer(call.kind, resp.Content)
+	}
+	if werr := c.Audit.Write(e); werr != nil {
+		log.Printf("[AI] could not write audit log: %v", werr)
+	}
+}
+
+// auditCached records a chunk answered from the verdict cache
+func (c *Client) auditCached(call auditCall, model, prompt string, results []FindingResult) {
+	if c.Audit == nil {
+		return
+	}
+	e := auditlog.Entry{
+		Time:       time.Now(),
+		RequestID:  call.id,
+		Kind:       call.kind,
+		File:       call.file,
+		Provider:   c.Provider.Name(),
+		Model:      model,
+		Escalated:  call.escalated,
+		PromptHash: c.Audit.Hash(prompt),
+		Status:     auditlog.StatusCached,
+		Findings:   c.hashFindings(results),
+	}
+	if werr := c.Audit.Write(e); werr != nil {
+		log.Printf("[AI] could not write audit log: %v", werr)
+	}
+}
+
+// redactAnswer keeps the parts of an answer that carry no document text
+func (c *Client) redactAnswer(kind, content string) (string, []auditlog.Finding) {
+	switch kind {
+	case auditChunk:
+		var resp struct {
+			Findings []FindingResult `json:"findings"`
+		}
+		if json.Unmarshal([]byte(cleanMarkdown(content)), &resp) != nil {
+			return "unparsable answer", nil
+		}
+		return "", c.hashFindings(resp.Findings)
+	case auditClassify:
+		var resp Classification
+		if json.Unmarshal([]byte(cleanMarkdown(content)), &resp) != nil {
+			return "unparsable answer", nil
+		}
+		return fmt.Sprintf("category=%s confidence=%.2f", resp.Category, resp.Confidence), nil
+	case auditValidate:
+		if strings.Contains(strings.ToUpper(content), "YES") {
+			return "YES", nil
+		}
+		return "NO", nil
+	}
+	return "", nil
+}
+
+func (c *Client) hashFindings(results []FindingResult) []auditlog.Finding {
+	findings := make([]auditlog.Finding, 0, len(results))
+	for _, f := range results {
+		findings = append(findings, auditlog.Finding{
+			Type:       f.Type,
+			ValueHash:  c.Audit.Hash(f.Value),
+			Confidence: f.Confidence,
+		})
+	}
+	return findings
+}
+
+// errorClass describes an error without the backend's output, which may
+// echo the prompt
+func errorClass(err error) string {
+	var httpErr *HTTPError
+	var netErr net.Error
+	var invalid *ValidationError
+	switch {
+	case errors.Is(err, ErrCircuitOpen):
+		return "circuit breaker open"
+	case errors.Is(err, context.Canceled):
+		return "cancelled"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.As(err, &httpErr):
+		return fmt.Sprintf("status %d", httpErr.StatusCode)
+	case errors.As(err, &invalid):
+		return "invalid answer"
+	case errors.As(err, &netErr):
+		return "connection failed"
+	}
+	return "request failed"
+}
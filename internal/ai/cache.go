@@ -0,0 +1,86 @@
+package ai
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/models"
+)
+
+// VerdictCache stores validated answers by cache key. Implementations must
+// be safe for concurrent use; storage.VerdictCache is the SQLite one.
+type VerdictCache interface {
+	Get(key string) (string, bool)
+	Put(key, verdict string)
+}
+
+var (
+	promptVersionOnce sync.Once
+	promptVersion     string
+)
+
+// PromptVersion is a hash of everything that shapes the model's answer apart
+// from the document: templates, per-type instructions and the schema. Editing
+// prompts.go therefore invalidates cached verdicts.
+func PromptVersion() string {
+	promptVersionOnce.Do(func() {
+		h := sha256.New()
+		h.Write([]byte(promptTemplateBase))
+		h.Write([]byte(repairPrompt))
+		h.Write([]byte(pseudonymInstructions))
+		h.Write([]byte(GetDefaultPrompt()))
+
+		types := make([]models.FindingType, 0, len(PromptTemplates))
+		for t := range PromptTemplates {
+			types = append(types, t)
+		}
+		sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+		for _, t := range types {
+			h.Write([]byte(t))
+			h.Write([]byte(PromptTemplates[t]))
+		}
+
+		schema, _ := json.Marshal(FindingsSchema)
+		h.Write(schema)
+		promptVersion = hex.EncodeToString(h.Sum(nil))[:16]
+	})
+	return promptVersion
+}
+
+// cacheKey identifies one verdict: hash(model, prompt version, context)
+func (c *Client) cacheKey(model, prompt string) string {
+	h := sha256.New()
+	for _, part := range []string{c.Provider.Name(), model, PromptVersion(), prompt} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// cachedFindings returns the verdict for key if the cache holds a valid one
+func (c *Client) cachedFindings(key string) ([]FindingResult, bool) {
+	if c.Cache == nil {
+		return nil, false
+	}
+	verdict, ok := c.Cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	var results []FindingResult
+	if err := json.Unmarshal([]byte(verdict), &results); err != nil {
+		return nil, false
+	}
+	return results, true
+}
+
+func (c *Client) storeFindings(key string, results []FindingResult) {
+	if c.Cache == nil {
+		return
+	}
+	if data, err := json.Marshal(results); err == nil {
+		c.Cache.Put(key, string(data))
+	}
+}
@@ -0,0 +1,85 @@
+package ai
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// charsPerToken is a conservative average for the Latin-script languages
+// the scanner targets; real tokenizers are model specific
+const charsPerToken = 4
+
+// EstimateTokens approximates the token count of text
+func EstimateTokens(text string) int {
+	return (len(text) + charsPerToken - 1) / charsPerToken
+}
+
+// Chunk is a window of document text sized for one model call.
+// Start and End are byte offsets into the document.
+type Chunk struct {
+	Start int
+	End   int
+	Text  string
+}
+
+// SplitChunks cuts text into chunks of about maxTokens tokens. Consecutive
+// chunks share overlapTokens tokens so values on a boundary appear whole in
+// at least one chunk. Cuts are moved back to a line break or space when one
+// is close, so words are not split.
+func SplitChunks(text string, maxTokens, overlapTokens int) []Chunk {
+	size := maxTokens * charsPerToken
+	overlap := overlapTokens * charsPerToken
+	if size <= 0 {
+		size = len(text)
+	}
+	if overlap >= size {
+		overlap = size / 4
+	}
+
+	var chunks []Chunk
+	start := 0
+	for start < len(text) {
+		end := start + size
+		if end >= len(text) {
+			end = len(text)
+		} else {
+			end = cutPoint(text, start+size/2, end)
+		}
+		chunks = append(chunks, Chunk{Start: start, End: end, Text: text[start:end]})
+		if end == len(text) {
+			break
+		}
+
+		next := end - overlap
+		// Do not start inside a multi-byte character either
+		for next > start && !utf8.RuneStart(text[next]) {
+			next--
+		}
+		if next <= start {
+			next = end
+		}
+		// Start the overlap on a word boundary as well
+		if i := strings.IndexAny(text[next:end], " \t\n"); i >= 0 && next+i+1 < end {
+			next += i + 1
+		}
+		start = next
+	}
+	return chunks
+}
+
+// cutPoint finds the last line break, or failing that the last whitespace,
+// in text[min:max]; max itself is used when there is neither
+func cutPoint(text string, min, max int) int {
+	window := text[min:max]
+	if i := strings.LastIndexByte(window, '\n'); i >= 0 {
+		return min + i + 1
+	}
+	if i := strings.LastIndexAny(window, " \t"); i >= 0 {
+		return min + i + 1
+	}
+	// Do not cut through a multi-byte character
+	for max > min && max < len(text) && text[max]&0xC0 == 0x80 {
+		max--
+	}
+	return max
+}
@@ -0,0 +1,68 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplitChunks(t *testing.T) {
+	var sb strings.Builder
+	for i := 0; i < 50; i++ {
+		sb.WriteString("Zeile mit etwas Text und einer Adresse darin.\n")
+	}
+	text := sb.String()
+
+	chunks := SplitChunks(text, 100, 10)
+	if len(chunks) < 4 {
+		t.Fatalf("%d chunks, want several", len(chunks))
+	}
+	if chunks[0].Start != 0 || chunks[len(chunks)-1].End != len(text) {
+		t.Errorf("chunks do not cover the text: %d-%d", chunks[0].Start, chunks[len(chunks)-1].End)
+	}
+	for i, c := range chunks {
+		if c.Text != text[c.Start:c.End] {
+			t.Errorf("chunk %d: text does not match its offsets", i)
+		}
+		if i > 0 {
+			prev := chunks[i-1]
+			if c.Start >= prev.End || c.Start <= prev.Start {
+				t.Errorf("chunk %d starts at %d, want inside the previous chunk %d-%d", i, c.Start, prev.Start, prev.End)
+			}
+		}
+		// Cuts fall on line breaks here
+		if c.End < len(text) && text[c.End-1] != '\n' {
+			t.Errorf("chunk %d ends inside a line", i)
+		}
+	}
+}
+
+func TestSplitChunksKeepsRunesWhole(t *testing.T) {
+	// No whitespace, so cuts and overlaps fall back to rune boundaries
+	text := strings.Repeat("äöüß東京", 200)
+
+	for _, overlap := range []int{0, 3, 7, 10} {
+		chunks := SplitChunks(text, 25, overlap)
+		if len(chunks) < 2 {
+			t.Fatalf("overlap %d: %d chunks, want several", overlap, len(chunks))
+		}
+		for i, c := range chunks {
+			if !utf8.ValidString(c.Text) {
+				t.Errorf("overlap %d: chunk %d (%d-%d) is not valid UTF-8", overlap, i, c.Start, c.End)
+			}
+		}
+		if chunks[len(chunks)-1].End != len(text) {
+			t.Errorf("overlap %d: last chunk ends at %d", overlap, chunks[len(chunks)-1].End)
+		}
+	}
+}
+
+func TestSplitChunksShortText(t *testing.T) {
+	chunks := SplitChunks("kurz", 100, 10)
+	if len(chunks) != 1 || chunks[0].Text != "kurz" {
+		t.Errorf("chunks = %+v", chunks)
+	}
+	if chunks := SplitChunks("", 100, 10); len(chunks) != 0 {
+		t.Errorf("empty text: %d chunks", len(chunks))
+	}
+}
@@ -0,0 +1,198 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/config"
+	"github.com/digimosa/ai-gdpr-scan/internal/models"
+)
+
+// newTestClient returns a client answering with the given responses in turn;
+// the last one repeats
+func newTestClient(t *testing.T, answers ...string) (*Client, *FakeProvider) {
+	t.Helper()
+	fake := &FakeProvider{Respond: func(req ChatRequest) (string, error) {
+		turn := (len(req.Messages) - 1) / 2
+		if turn >= len(answers) {
+			turn = len(answers) - 1
+		}
+		return answers[turn], nil
+	}}
+
+	cfg := config.DefaultConfig()
+	cfg.AIModel = "fake"
+	cfg.AIAuditLog = ""
+	cfg.AIRetryDelay = 0
+	return NewClientWithProvider(cfg, fake), fake
+}
+
+func testChunkRequest() ChunkRequest {
+	return ChunkRequest{
+		Label:      "letter.txt, part 1 of 1",
+		Text:       "IBAN DE89370400440532013000, Max Mustermann",
+		Candidates: "MATCH[0]: Type=IBAN Value='DE89370400440532013000' Offset=5\n",
+		Types:      []models.FindingType{models.TypeIBAN},
+	}
+}
+
+func TestAnalyzeChunk(t *testing.T) {
+	client, fake := newTestClient(t, `{"findings": [{"type": "IBAN", "value": "DE89370400440532013000", "reason": "labelled", "confidence": 0.9}]}`)
+
+	results, err := client.AnalyzeChunk(context.Background(), testChunkRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Type != "IBAN" {
+		t.Fatalf("results = %+v", results)
+	}
+
+	calls := fake.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("%d requests, want 1", len(calls))
+	}
+	if calls[0].Model != "fake" || calls[0].Schema == nil || !calls[0].JSON {
+		t.Errorf("request model %q, schema %v, JSON %v", calls[0].Model, calls[0].Schema != nil, calls[0].JSON)
+	}
+	prompt := calls[0].Messages[0].Content
+	for _, want := range []string{"letter.txt, part 1 of 1", "MATCH[0]: Type=IBAN", "DE89370400440532013000"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt does not contain %q", want)
+		}
+	}
+}
+
+func TestAnalyzeChunkRepairsInvalidAnswers(t *testing.T) {
+	client, fake := newTestClient(t,
+		"Sure! I found an IBAN.",
+		`{"findings": [{"type": "IBAN", "value": "DE89370400440532013000", "reason": "labelled", "confidence": 0.9}]}`,
+	)
+
+	results, err := client.AnalyzeChunk(context.Background(), testChunkRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("results = %+v", results)
+	}
+
+	calls := fake.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("%d requests, want 2", len(calls))
+	}
+	repair := calls[1].Messages
+	if len(repair) != 3 || repair[1].Role != "assistant" || !strings.Contains(repair[2].Content, "not valid JSON") {
+		t.Errorf("repair conversation = %+v", repair)
+	}
+
+	stats := client.Stats()
+	if stats.InvalidJSON != 1 || stats.RepairAttempts != 1 || stats.Repaired != 1 || stats.Rejected != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestAnalyzeChunkKeepsValidFindingsOfPartialAnswers(t *testing.T) {
+	partial := `{"findings": [
+		{"type": "IBAN", "value": "DE89370400440532013000", "reason": "labelled", "confidence": 0.9},
+		{"type": "Name", "value": "Max Mustermann", "reason": "salutation", "confidence": 7}
+	]}`
+	client, fake := newTestClient(t, partial)
+
+	results, err := client.AnalyzeChunk(context.Background(), testChunkRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Type != "IBAN" {
+		t.Errorf("results = %+v, want the IBAN only", results)
+	}
+	if n := len(fake.Calls()); n != 1+client.MaxRepairs {
+		t.Errorf("%d requests, want %d", n, 1+client.MaxRepairs)
+	}
+	stats := client.Stats()
+	if stats.Dropped != 1 || stats.Rejected != 0 || stats.SchemaErrors != int64(1+client.MaxRepairs) {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestAnalyzeChunkRejectsAfterRepairs(t *testing.T) {
+	client, fake := newTestClient(t, `{"results": []}`)
+
+	_, err := client.AnalyzeChunk(context.Background(), testChunkRequest())
+	if !IsValidationError(err) {
+		t.Fatalf("err = %v, want a ValidationError", err)
+	}
+	if n := len(fake.Calls()); n != 1+client.MaxRepairs {
+		t.Errorf("%d requests, want %d", n, 1+client.MaxRepairs)
+	}
+	if stats := client.Stats(); stats.Rejected != 1 {
+		t.Errorf("rejected = %d, want 1", stats.Rejected)
+	}
+}
+
+func TestAnalyzeChunkBackendError(t *testing.T) {
+	client, _ := newTestClient(t, "")
+	client.Provider.(*Dispatcher).Provider = &FakeProvider{Respond: func(ChatRequest) (string, error) {
+		return "", &HTTPError{StatusCode: 400, Body: "bad request"}
+	}}
+
+	_, err := client.AnalyzeChunk(context.Background(), testChunkRequest())
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || IsValidationError(err) {
+		t.Errorf("err = %v, want the HTTP error", err)
+	}
+}
+
+func TestParseTypes(t *testing.T) {
+	types, err := ParseTypes([]string{"iban", " creditcard ", ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(types, ",") != "IBAN,CreditCard" {
+		t.Errorf("types = %v", types)
+	}
+	if _, err := ParseTypes([]string{"Address"}); err == nil {
+		t.Error("expected an error for an unknown type")
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.AISkipTypes = []string{"iban"}
+	reviewed, skipped := NewRouter(cfg).Skip([]models.Match{{Type: models.TypeIBAN}, {Type: models.TypeEmail}})
+	if len(reviewed) != 1 || len(skipped) != 1 || skipped[0].Type != models.TypeIBAN {
+		t.Errorf("reviewed %+v, skipped %+v", reviewed, skipped)
+	}
+}
+
+func TestModelCosts(t *testing.T) {
+	costs, err := ParseModelCosts([]string{"fake=0.5", " other = 2 "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if costs["fake"] != 0.5 || costs["other"] != 2 {
+		t.Errorf("costs = %v", costs)
+	}
+	for _, bad := range []string{"fake", "=1", "fake=cheap", "fake=-1"} {
+		if _, err := ParseModelCosts([]string{bad}); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.AIModel, cfg.AIAuditLog, cfg.AIModelCosts = "fake", "", costs
+	fake := &FakeProvider{}
+	client := NewClientWithProvider(cfg, fake)
+	if _, err := client.AnalyzeChunk(context.Background(), testChunkRequest()); err != nil {
+		t.Fatal(err)
+	}
+	resp, _ := fake.Chat(context.Background(), fake.Calls()[0])
+	tokens := resp.PromptTokens + resp.CompletionTokens
+
+	stats := client.Stats().Models
+	if len(stats) != 1 || stats[0].Model != "fake" {
+		t.Fatalf("model stats = %+v", stats)
+	}
+	if want := float64(tokens) / 1000 * 0.5; stats[0].Cost != want {
+		t.Errorf("cost = %v for %d tokens, want %v", stats[0].Cost, tokens, want)
+	}
+}
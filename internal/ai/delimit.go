@@ -0,0 +1,22 @@
+package ai
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// documentMarkers returns the markers that enclose document text in a prompt.
+// They carry a nonce derived from the text itself: a document cannot contain
+// its own closing marker, since that would change the nonce, yet identical
+// text still produces identical prompts for the verdict cache.
+func documentMarkers(text string) (begin, end string) {
+	sum := sha256.Sum256([]byte(PromptVersion() + text))
+	nonce := hex.EncodeToString(sum[:6])
+	return "<<<DOCUMENT " + nonce + ">>>", "<<<END DOCUMENT " + nonce + ">>>"
+}
+
+// escapeDocument breaks up sequences that resemble the prompt's own markers
+func escapeDocument(text string) string {
+	return strings.NewReplacer("<<<", "<< <", ">>>", "> >>").Replace(text)
+}
@@ -0,0 +1,275 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/config"
+)
+
+// listTimeout bounds the model list and model detail requests
+const listTimeout = 10 * time.Second
+
+// maxWarmUps caps the models tried before falling back to regex-only, as
+// every warm-up may load a model into memory
+const maxWarmUps = 3
+
+// Readiness is the outcome of the startup diagnostics, see Client.Diagnose
+type Readiness struct {
+	Provider  string
+	Model     string      // Configured model
+	Installed []ModelInfo // Nil when the backend cannot list its models
+	Listed    bool        // The backend listed its models
+
+	UseModel   string     // Model the scan will use; empty means regex-only
+	Fallback   bool       // UseModel replaces the configured model
+	Info       *ModelInfo // Details of UseModel, if the backend reports them
+	WarmUp     time.Duration
+	LargeModel string // Large model the scan will use; empty disables it
+	EmbedModel string // Embedding model of the false-positive filter; empty disables it
+
+	Warnings []string
+}
+
+// RegexOnly reports whether no model answered
+func (r *Readiness) RegexOnly() bool {
+	return r.UseModel == ""
+}
+
+func (r *Readiness) warn(format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// Diagnose checks the backend before a scan. It lists the installed models
+// where the backend supports it, looks up the context window and
+// quantization of the configured model and times a short warm-up request.
+// When the configured model is missing or does not answer, the next
+// candidate from cfg.AIFallbackModels (or any installed chat model) is
+// tried. Diagnose talks to the backend directly, so failures neither count
+// in the scan statistics nor trip the circuit breaker.
+func (c *Client) Diagnose(ctx context.Context, cfg *config.Config) *Readiness {
+	backend := c.backend()
+	r := &Readiness{Provider: backend.Name(), Model: c.Model}
+
+	candidates := []string{c.Model}
+	inspector, _ := backend.(ModelInspector)
+	if inspector != nil {
+		listCtx, cancel := context.WithTimeout(ctx, listTimeout)
+		installed, err := inspector.ListModels(listCtx)
+		cancel()
+		if err != nil {
+			r.warn("cannot list models: %v", err)
+			return r
+		}
+		r.Installed, r.Listed = installed, true
+		candidates = r.candidates(cfg.AIFallbackModels)
+		if findModel(installed, c.Model) == "" {
+			r.warn("model %s is not installed", c.Model)
+		}
+	}
+
+	for i, model := range candidates {
+		if i == maxWarmUps {
+			break
+		}
+		latency, err := warmUp(ctx, backend, model, cfg.AIWarmupTimeout)
+		if err != nil {
+			r.warn("model %s did not answer: %v", model, err)
+			continue
+		}
+		r.UseModel, r.WarmUp = model, latency
+		r.Fallback = model != c.Model
+		break
+	}
+	if r.RegexOnly() {
+		return r
+	}
+
+	if inspector != nil {
+		showCtx, cancel := context.WithTimeout(ctx, listTimeout)
+		info, err := inspector.ShowModel(showCtx, r.UseModel)
+		cancel()
+		if err != nil {
+			r.warn("cannot read details of %s: %v", r.UseModel, err)
+		} else {
+			r.Info = info
+			r.checkContext(cfg)
+		}
+	}
+
+	r.LargeModel = cfg.AILargeModel
+	if r.LargeModel != "" && r.Listed {
+		if findModel(r.Installed, r.LargeModel) == "" {
+			r.warn("large model %s is not installed, all types go to %s", r.LargeModel, r.UseModel)
+			r.LargeModel = ""
+		}
+	}
+
+	if cfg.AIEmbedFilter {
+		r.EmbedModel = cfg.AIEmbedModel
+		if r.Listed && findModel(r.Installed, r.EmbedModel) == "" {
+			r.warn("embedding model %s is not installed, the false-positive filter is off", r.EmbedModel)
+			r.EmbedModel = ""
+		}
+	}
+	return r
+}
+
+// candidates orders the installed models to try: the configured model, the
+// configured fallbacks and, without fallbacks, every other chat model
+func (r *Readiness) candidates(fallbacks []string) []string {
+	var out []string
+	add := func(name string) {
+		if findModel(r.Installed, name) == "" {
+			return
+		}
+		for _, n := range out {
+			if sameModel(n, name) {
+				return
+			}
+		}
+		out = append(out, name)
+	}
+
+	add(r.Model)
+	for _, name := range fallbacks {
+		add(strings.TrimSpace(name))
+	}
+	if len(fallbacks) == 0 {
+		for _, m := range r.Installed {
+			if !isEmbeddingModel(m) {
+				add(m.Name)
+			}
+		}
+	}
+	return out
+}
+
+// checkContext warns when the chunk prompts do not fit the context window
+func (r *Readiness) checkContext(cfg *config.Config) {
+	window := r.Info.NumCtx
+	if window == 0 {
+		window = r.Info.ContextLength
+	}
+	needed := EstimateTokens(promptTemplateBase) + cfg.AIChunkTokens + cfg.AIFewShotTokens
+	if window > 0 && window < needed {
+		r.warn("context window of %d tokens is smaller than a chunk prompt (about %d tokens); lower the chunk size", window, needed)
+	}
+}
+
+// warmUp sends a minimal request, which also makes the backend load the
+// model, and returns its latency
+func warmUp(ctx context.Context, backend Provider, model string, timeout time.Duration) (time.Duration, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	_, err := backend.Chat(ctx, ChatRequest{
+		Model:    model,
+		Messages: []Message{{Role: "user", Content: "Reply with OK."}},
+	})
+	return time.Since(start), err
+}
+
+// findModel returns the installed name of model, or "" if it is not installed
+func findModel(installed []ModelInfo, model string) string {
+	if model == "" {
+		return ""
+	}
+	for _, m := range installed {
+		if sameModel(m.Name, model) {
+			return m.Name
+		}
+	}
+	return ""
+}
+
+// sameModel compares model names, accepting Ollama's implicit ":latest" tag
+func sameModel(a, b string) bool {
+	return strings.TrimSuffix(a, ":latest") == strings.TrimSuffix(b, ":latest")
+}
+
+// isEmbeddingModel recognizes models that cannot chat, e.g. nomic-embed-text
+func isEmbeddingModel(m ModelInfo) bool {
+	return strings.Contains(strings.ToLower(m.Name), "embed") || strings.Contains(strings.ToLower(m.Family), "bert")
+}
+
+// Apply switches cfg and the client to the chosen models, or disables the
+// AI when no model answered
+func (r *Readiness) Apply(cfg *config.Config, c *Client) {
+	if r.RegexOnly() {
+		cfg.DisableAI = true
+		return
+	}
+	cfg.AIModel, c.Model = r.UseModel, r.UseModel
+	cfg.AILargeModel = r.LargeModel
+	cfg.AIEmbedFilter = r.EmbedModel != ""
+	c.Router.SmallModel, c.Router.LargeModel = r.UseModel, r.LargeModel
+	if r.LargeModel == r.UseModel {
+		c.Router.LargeModel = ""
+	}
+}
+
+// Write prints the readiness report
+func (r *Readiness) Write(w io.Writer) {
+	fmt.Fprintf(w, "AI readiness (%s)\n", r.Provider)
+	if r.Listed {
+		names := make([]string, 0, len(r.Installed))
+		for _, m := range r.Installed {
+			names = append(names, m.Name)
+		}
+		fmt.Fprintf(w, "  Installed models: %d (%s)\n", len(names), strings.Join(names, ", "))
+	}
+	if !r.RegexOnly() {
+		fmt.Fprintf(w, "  Model:            %s%s\n", r.UseModel, r.Info.describe())
+		fmt.Fprintf(w, "  Warm-up:          %s\n", r.WarmUp.Round(time.Millisecond))
+		if r.LargeModel != "" {
+			fmt.Fprintf(w, "  Large model:      %s\n", r.LargeModel)
+		}
+		if r.EmbedModel != "" {
+			fmt.Fprintf(w, "  Embedding model:  %s (false-positive filter)\n", r.EmbedModel)
+		}
+	}
+	for _, warning := range r.Warnings {
+		fmt.Fprintf(w, "  [WARN] %s\n", warning)
+	}
+
+	switch {
+	case r.RegexOnly():
+		fmt.Fprintln(w, "  Mode:             regex-only (no model answered)")
+	case r.Fallback:
+		fmt.Fprintf(w, "  Mode:             AI analysis with fallback model %s instead of %s\n", r.UseModel, r.Model)
+	default:
+		fmt.Fprintln(w, "  Mode:             AI analysis")
+	}
+}
+
+// describe formats the known details, e.g. " (llama 3.2B, Q4_K_M, context 131072 tokens)"
+func (m *ModelInfo) describe() string {
+	if m == nil {
+		return ""
+	}
+	var parts []string
+	if m.Family != "" || m.ParameterSize != "" {
+		parts = append(parts, strings.TrimSpace(m.Family+" "+m.ParameterSize))
+	}
+	if m.Quantization != "" {
+		parts = append(parts, m.Quantization)
+	}
+	if m.ContextLength > 0 {
+		parts = append(parts, fmt.Sprintf("context %d tokens", m.ContextLength))
+	}
+	if m.NumCtx > 0 {
+		parts = append(parts, fmt.Sprintf("num_ctx %d", m.NumCtx))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
@@ -0,0 +1,195 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/config"
+)
+
+// ErrCircuitOpen is returned without contacting the backend while the
+// circuit breaker considers it down
+var ErrCircuitOpen = errors.New("AI backend unavailable (circuit breaker open)")
+
+// Dispatcher wraps a Provider shared by all scan workers. It caps the number
+// of requests in flight, retries 5xx answers and timeouts with exponential
+// backoff and jitter, and opens a circuit breaker after repeated failures so
+// the scan continues regex-only instead of waiting on a dead backend.
+type Dispatcher struct {
+	Provider Provider
+
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+
+	// Breaker: after Threshold consecutive failed requests no request is sent
+	// for Cooldown; then a single probe decides whether to close it again
+	Threshold int
+	Cooldown  time.Duration
+
+	slots chan struct{}
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+
+	retries int64
+	trips   int64
+}
+
+// NewDispatcher wraps provider with the limits configured in cfg
+func NewDispatcher(cfg *config.Config, provider Provider) *Dispatcher {
+	inFlight := cfg.AIMaxInFlight
+	if inFlight <= 0 {
+		inFlight = 1
+	}
+	return &Dispatcher{
+		Provider:   provider,
+		MaxRetries: cfg.AIMaxRetries,
+		BaseDelay:  cfg.AIRetryDelay,
+		MaxDelay:   30 * time.Second,
+		Threshold:  cfg.AIBreakerThreshold,
+		Cooldown:   cfg.AIBreakerCooldown,
+		slots:      make(chan struct{}, inFlight),
+	}
+}
+
+func (d *Dispatcher) Name() string {
+	return d.Provider.Name()
+}
+
+func (d *Dispatcher) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	ok, probe := d.allow()
+	if !ok {
+		return nil, ErrCircuitOpen
+	}
+
+	select {
+	case d.slots <- struct{}{}:
+		defer func() { <-d.slots }()
+	case <-ctx.Done():
+		d.release(probe)
+		return nil, ctx.Err()
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := d.Provider.Chat(ctx, req)
+		if err == nil {
+			d.record(true, probe)
+			return resp, nil
+		}
+		if ctx.Err() != nil {
+			// Cancelled by the scanner, not a backend failure
+			d.release(probe)
+			return nil, ctx.Err()
+		}
+		if !retryable(err) || attempt >= d.MaxRetries {
+			d.record(!isBackendFailure(err), probe)
+			return nil, err
+		}
+
+		atomic.AddInt64(&d.retries, 1)
+		select {
+		case <-time.After(d.backoff(attempt)):
+		case <-ctx.Done():
+			d.release(probe)
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Open reports whether the breaker currently rejects requests
+func (d *Dispatcher) Open() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return time.Now().Before(d.openUntil)
+}
+
+// allow decides whether a request may be sent. Once the cooldown has passed
+// exactly one probe request is let through; probe tells the caller it holds
+// it and must hand it back with release or record.
+func (d *Dispatcher) allow() (ok, probe bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.openUntil.IsZero() {
+		return true, false
+	}
+	if time.Now().Before(d.openUntil) || d.probing {
+		return false, false
+	}
+	d.probing = true
+	return true, true
+}
+
+// release gives back the probe slot without judging the backend. Requests
+// that do not hold the probe leave it alone.
+func (d *Dispatcher) release(probe bool) {
+	if !probe {
+		return
+	}
+	d.mu.Lock()
+	d.probing = false
+	d.mu.Unlock()
+}
+
+// record updates the breaker with the outcome of a request; probe as
+// returned by allow
+func (d *Dispatcher) record(ok, probe bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if probe {
+		d.probing = false
+	}
+
+	if ok {
+		d.failures = 0
+		d.openUntil = time.Time{}
+		return
+	}
+
+	d.failures++
+	if d.Threshold > 0 && d.failures >= d.Threshold {
+		if !time.Now().Before(d.openUntil) {
+			atomic.AddInt64(&d.trips, 1)
+		}
+		d.openUntil = time.Now().Add(d.Cooldown)
+	}
+}
+
+// backoff is BaseDelay * 2^attempt plus up to 50% jitter, capped at MaxDelay
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	delay := d.BaseDelay << uint(attempt)
+	if delay <= 0 || delay > d.MaxDelay {
+		delay = d.MaxDelay
+	}
+	return delay + time.Duration(rand.Int63n(int64(delay)/2+1))
+}
+
+// retryable reports errors worth another attempt: 5xx and 429 answers and timeouts
+func retryable(err error) bool {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= 500 || httpErr.StatusCode == 429
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+// isBackendFailure reports errors that indicate the backend is down or
+// overloaded, as opposed to a request it rejected
+func isBackendFailure(err error) bool {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= 500 || httpErr.StatusCode == 429
+	}
+	return true // Connection refused, DNS, timeouts
+}
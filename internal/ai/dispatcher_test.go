@@ -0,0 +1,83 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func chatRequest(content string) ChatRequest {
+	return ChatRequest{Messages: []Message{{Role: "user", Content: content}}}
+}
+
+func TestDispatcherKeepsProbeWhenOtherRequestsAreCancelled(t *testing.T) {
+	unblock := map[string]chan struct{}{
+		"slow":  make(chan struct{}),
+		"probe": make(chan struct{}),
+	}
+	started := make(chan string, 2)
+	fake := &FakeProvider{Respond: func(req ChatRequest) (string, error) {
+		content := req.Messages[0].Content
+		if content == "fail" {
+			return "", &HTTPError{StatusCode: 503, Body: "overloaded"}
+		}
+		if ch, ok := unblock[content]; ok {
+			started <- content
+			<-ch
+		}
+		if content == "slow" {
+			// The fake does not watch the context itself
+			return "", context.Canceled
+		}
+		return `{"findings": []}`, nil
+	}}
+	d := &Dispatcher{
+		Provider:  fake,
+		Threshold: 1,
+		Cooldown:  20 * time.Millisecond,
+		slots:     make(chan struct{}, 4),
+	}
+
+	// A request in flight while the breaker is closed holds no probe
+	ctx, cancel := context.WithCancel(context.Background())
+	slow := make(chan error, 1)
+	go func() {
+		_, err := d.Chat(ctx, chatRequest("slow"))
+		slow <- err
+	}()
+	<-started
+
+	if _, err := d.Chat(context.Background(), chatRequest("fail")); err == nil {
+		t.Fatal("expected the backend error")
+	}
+	if _, err := d.Chat(context.Background(), chatRequest("other")); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("err = %v, want the breaker open", err)
+	}
+
+	time.Sleep(d.Cooldown)
+	probe := make(chan error, 1)
+	go func() {
+		_, err := d.Chat(context.Background(), chatRequest("probe"))
+		probe <- err
+	}()
+	<-started
+
+	// Cancelling the older request must not hand out a second probe
+	cancel()
+	close(unblock["slow"])
+	if err := <-slow; !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled request: err = %v", err)
+	}
+	if _, err := d.Chat(context.Background(), chatRequest("other")); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("err = %v while the probe is outstanding, want the breaker open", err)
+	}
+
+	close(unblock["probe"])
+	if err := <-probe; err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if _, err := d.Chat(context.Background(), chatRequest("other")); err != nil {
+		t.Errorf("err = %v after a successful probe, want the breaker closed", err)
+	}
+}
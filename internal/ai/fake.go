@@ -0,0 +1,55 @@
+package ai
+
+import (
+	"context"
+	"sync"
+)
+
+// FakeProvider is an in-process Provider for tests and offline runs.
+// Respond decides the answer for each request; without it the fake
+// answers with an empty finding list.
+type FakeProvider struct {
+	Respond func(req ChatRequest) (string, error)
+
+	mu    sync.Mutex
+	calls []ChatRequest
+}
+
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakeProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.calls = append(p.calls, req)
+	p.mu.Unlock()
+
+	content := `{"findings": []}`
+	if p.Respond != nil {
+		var err error
+		if content, err = p.Respond(req); err != nil {
+			return nil, err
+		}
+	}
+
+	prompt := 0
+	for _, m := range req.Messages {
+		prompt += EstimateTokens(m.Content)
+	}
+	return &ChatResponse{
+		Content:          content,
+		PromptTokens:     prompt,
+		CompletionTokens: EstimateTokens(content),
+	}, nil
+}
+
+// Calls returns the requests received so far
+func (p *FakeProvider) Calls() []ChatRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]ChatRequest(nil), p.calls...)
+}
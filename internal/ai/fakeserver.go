@@ -0,0 +1,174 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FakeServer serves the Ollama /api/chat, /api/tags, /api/show and
+// /api/embed endpoints from a FakeProvider, so the complete HTTP path can
+// run without a model. The only installed model is "fake"; embeddings are
+// hashed word counts, so texts sharing words are similar.
+type FakeServer struct {
+	URL      string // Base URL to use as config.AIURL
+	Provider *FakeProvider
+
+	listener net.Listener
+	server   *http.Server
+}
+
+// StartFakeServer listens on a free local port until Close is called
+func StartFakeServer(provider *FakeProvider) (*FakeServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &FakeServer{
+		URL:      "http://" + listener.Addr().String(),
+		Provider: provider,
+		listener: listener,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/chat", s.handleChat)
+	mux.HandleFunc("/api/tags", s.handleTags)
+	mux.HandleFunc("/api/show", s.handleShow)
+	mux.HandleFunc("/api/embed", s.handleEmbed)
+	s.server = &http.Server{Handler: mux}
+
+	go s.server.Serve(listener)
+	return s, nil
+}
+
+// Close stops the server
+func (s *FakeServer) Close() error {
+	return s.server.Close()
+}
+
+func (s *FakeServer) handleChat(w http.ResponseWriter, r *http.Request) {
+	var req ollamaChatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp, err := s.Provider.Chat(r.Context(), ChatRequest{
+		Model:    req.Model,
+		Messages: req.Messages,
+		JSON:     req.Format != nil,
+	})
+	if err != nil {
+		status := http.StatusInternalServerError
+		if httpErr, ok := err.(*HTTPError); ok {
+			status = httpErr.StatusCode
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ollamaChatResponse{
+		Message:         Message{Role: "assistant", Content: resp.Content},
+		Done:            true,
+		PromptEvalCount: resp.PromptTokens,
+		EvalCount:       resp.CompletionTokens,
+	})
+}
+
+func (s *FakeServer) handleTags(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, `{"models": [{"name": "fake:latest", "size": 0, "details": {"family": "fake", "quantization_level": "F16"}}]}`)
+}
+
+func (s *FakeServer) handleShow(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Model string `json:"model"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !sameModel(req.Model, "fake") {
+		http.Error(w, fmt.Sprintf(`{"error": "model '%s' not found"}`, req.Model), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, `{"details": {"family": "fake", "quantization_level": "F16"}, "model_info": {"fake.context_length": 8192}}`)
+}
+
+// fakeEmbeddingSize is the dimension of FakeServer embeddings
+const fakeEmbeddingSize = 256
+
+func (s *FakeServer) handleEmbed(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Input []string `json:"input"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	out := struct {
+		Embeddings [][]float64 `json:"embeddings"`
+	}{Embeddings: make([][]float64, 0, len(req.Input))}
+	for _, text := range req.Input {
+		vector := make([]float64, fakeEmbeddingSize)
+		for _, word := range strings.Fields(strings.ToLower(text)) {
+			h := fnv.New32a()
+			h.Write([]byte(word))
+			vector[h.Sum32()%fakeEmbeddingSize]++
+		}
+		out.Embeddings = append(out.Embeddings, vector)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(out)
+}
+
+var candidateLine = regexp.MustCompile(`(?m)^MATCH\[\d+\]: Type=(\S+).*? Value='(.*)' Offset=\d+$`)
+var candidateScore = regexp.MustCompile(` Score=([0-9.]+)`)
+
+// ConfirmCandidates is a deterministic FakeProvider.Respond that confirms
+// every detector candidate listed in the prompt with its detector score.
+// It makes the AI stage of an evaluation reproducible without a model.
+func ConfirmCandidates(req ChatRequest) (string, error) {
+	var prompt strings.Builder
+	for _, m := range req.Messages {
+		if m.Role == "user" {
+			prompt.WriteString(m.Content)
+			prompt.WriteString("\n")
+		}
+	}
+
+	type finding struct {
+		Type       string  `json:"type"`
+		Value      string  `json:"value"`
+		Reason     string  `json:"reason"`
+		Confidence float64 `json:"confidence"`
+	}
+	findings := []finding{}
+	for _, line := range candidateLine.FindAllStringSubmatch(prompt.String(), -1) {
+		t, ok := canonicalType(line[1])
+		if !ok {
+			continue
+		}
+		confidence := 0.8
+		if m := candidateScore.FindStringSubmatch(line[0]); m != nil {
+			confidence, _ = strconv.ParseFloat(m[1], 64)
+		}
+		findings = append(findings, finding{
+			Type:       string(t),
+			Value:      strings.TrimSpace(line[2]),
+			Reason:     "Detector candidate",
+			Confidence: confidence,
+		})
+	}
+
+	out, err := json.Marshal(map[string]interface{}{"findings": findings})
+	return string(out), err
+}
@@ -0,0 +1,117 @@
+package ai
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/models"
+)
+
+// Example is an anonymized finding a reviewer labelled through the dashboard
+type Example struct {
+	Type    models.FindingType
+	Subtype string
+	Text    string
+	Correct bool // Confirmed as personal data; false for a false positive
+}
+
+// ExampleSet holds the labelled examples available for few-shot prompting
+type ExampleSet struct {
+	PerType     int // Examples shown per finding type
+	TokenBudget int // Upper bound for the whole examples section
+	byType      map[models.FindingType][]Example
+}
+
+// NewExampleSet deduplicates examples and orders them for diverse selection:
+// per type, confirmed findings and false positives of each subtype alternate
+func NewExampleSet(examples []Example, perType, tokenBudget int) *ExampleSet {
+	groups := make(map[models.FindingType]map[string][]Example)
+	var groupKeys = make(map[models.FindingType][]string)
+	seen := make(map[string]bool)
+
+	for _, ex := range examples {
+		key := string(ex.Type) + "\x00" + strings.ToLower(strings.Join(strings.Fields(ex.Text), " "))
+		if ex.Text == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		group := fmt.Sprintf("%t/%s", ex.Correct, ex.Subtype)
+		if groups[ex.Type] == nil {
+			groups[ex.Type] = make(map[string][]Example)
+		}
+		if _, ok := groups[ex.Type][group]; !ok {
+			groupKeys[ex.Type] = append(groupKeys[ex.Type], group)
+		}
+		groups[ex.Type][group] = append(groups[ex.Type][group], ex)
+	}
+
+	set := &ExampleSet{PerType: perType, TokenBudget: tokenBudget, byType: make(map[models.FindingType][]Example)}
+	for t, keys := range groupKeys {
+		sort.Strings(keys)
+		// Round robin over the groups so one kind of example cannot crowd out the others
+		for i := 0; ; i++ {
+			added := false
+			for _, k := range keys {
+				if i < len(groups[t][k]) {
+					set.byType[t] = append(set.byType[t], groups[t][k][i])
+					added = true
+				}
+			}
+			if !added {
+				break
+			}
+		}
+	}
+	return set
+}
+
+// Len returns the number of distinct examples
+func (s *ExampleSet) Len() int {
+	n := 0
+	for _, ex := range s.byType {
+		n += len(ex)
+	}
+	return n
+}
+
+// Render returns the examples section for the given types, or "" if there
+// are none. Types are served in turn so the budget is shared between them.
+func (s *ExampleSet) Render(types []models.FindingType) string {
+	if s == nil || s.PerType <= 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	tokens := 0
+	for i := 0; i < s.PerType; i++ {
+		for _, t := range types {
+			if i >= len(s.byType[t]) {
+				continue
+			}
+			line := renderExample(s.byType[t][i])
+			if tokens+EstimateTokens(line) > s.TokenBudget {
+				continue
+			}
+			tokens += EstimateTokens(line)
+			sb.WriteString(line)
+		}
+	}
+	if sb.Len() == 0 {
+		return ""
+	}
+	return "\nExamples reviewed by our data protection team (values anonymized):\n" + sb.String()
+}
+
+func renderExample(ex Example) string {
+	verdict := "false positive, not personal data"
+	if ex.Correct {
+		verdict = "personal data"
+	}
+	label := string(ex.Type)
+	if ex.Subtype != "" {
+		label += "/" + ex.Subtype
+	}
+	return fmt.Sprintf("- [%s] %q -> %s\n", label, ex.Text, verdict)
+}
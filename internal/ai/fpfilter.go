@@ -0,0 +1,249 @@
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/config"
+	"github.com/digimosa/ai-gdpr-scan/internal/models"
+)
+
+// embedBatch is the number of texts sent per embeddings request
+const embedBatch = 64
+
+// minNeighbours is the smallest vote that may drop a candidate
+const minNeighbours = 3
+
+// EmbeddingCache stores vectors by cache key. Implementations must be safe
+// for concurrent use; storage.EmbeddingCache is the SQLite one.
+type EmbeddingCache interface {
+	Get(key string) ([]float64, bool)
+	Put(key string, vector []float64)
+}
+
+// FPFilter drops detector candidates before they reach the model when their
+// snippet, the value with its surrounding text, embeds close to findings
+// reviewers marked "Incorrect". Each candidate is compared with the reviewed
+// findings of its type; it is dropped when at least DropShare of its K
+// nearest neighbours with a cosine similarity of MinSimilarity or more are
+// false positives.
+type FPFilter struct {
+	Model         string
+	K             int
+	MinSimilarity float64
+	DropShare     float64
+
+	types map[models.FindingType]bool
+	refs  map[models.FindingType][]reference
+}
+
+type reference struct {
+	vector  []float64
+	correct bool
+}
+
+// Vote is the nearest-neighbour verdict on one candidate
+type Vote struct {
+	Neighbours int     // Reviewed findings within MinSimilarity, at most K
+	Incorrect  int     // Neighbours marked "Incorrect"
+	Similarity float64 // Similarity of the nearest neighbour
+	Drop       bool
+}
+
+// NewFPFilter embeds reviewer-labelled findings as the references of the
+// filter. Keyword matches of any type are filtered, so every example is
+// embedded, not only those of cfg.AIEmbedTypes.
+func (c *Client) NewFPFilter(ctx context.Context, cfg *config.Config, examples []Example) (*FPFilter, error) {
+	f := &FPFilter{
+		Model:         cfg.AIEmbedModel,
+		K:             cfg.AIEmbedNeighbours,
+		MinSimilarity: cfg.AIEmbedMinSimilarity,
+		DropShare:     cfg.AIEmbedDropShare,
+		types:         typeSet(cfg.AIEmbedTypes),
+		refs:          make(map[models.FindingType][]reference),
+	}
+
+	var texts []string
+	var kept []Example
+	for _, ex := range examples {
+		if strings.TrimSpace(ex.Text) != "" {
+			texts = append(texts, ex.Text)
+			kept = append(kept, ex)
+		}
+	}
+	vectors, err := c.Embed(ctx, f.Model, texts)
+	if err != nil {
+		return nil, err
+	}
+	for i, ex := range kept {
+		f.refs[ex.Type] = append(f.refs[ex.Type], reference{vector: vectors[i], correct: ex.Correct})
+	}
+	return f, nil
+}
+
+// Applies reports whether candidates of the match's type are filtered.
+// Keyword matches are always filtered.
+func (f *FPFilter) Applies(m models.Match) bool {
+	return m.Keyword || f.types[m.Type]
+}
+
+// References is the number of reviewed findings the filter compares with
+func (f *FPFilter) References() int {
+	n := 0
+	for _, refs := range f.refs {
+		n += len(refs)
+	}
+	return n
+}
+
+// Vote compares a candidate's vector with the reviewed findings of its type
+func (f *FPFilter) Vote(t models.FindingType, vector []float64) Vote {
+	type neighbour struct {
+		sim     float64
+		correct bool
+	}
+	var near []neighbour
+	for _, ref := range f.refs[t] {
+		if sim := cosine(vector, ref.vector); sim >= f.MinSimilarity {
+			near = append(near, neighbour{sim: sim, correct: ref.correct})
+		}
+	}
+	sort.Slice(near, func(i, j int) bool { return near[i].sim > near[j].sim })
+	if len(near) > f.K {
+		near = near[:f.K]
+	}
+
+	var v Vote
+	for _, n := range near {
+		v.Neighbours++
+		if !n.correct {
+			v.Incorrect++
+		}
+	}
+	if len(near) > 0 {
+		v.Similarity = near[0].sim
+	}
+	v.Drop = v.Neighbours >= minNeighbours && float64(v.Incorrect) >= f.DropShare*float64(v.Neighbours)
+	return v
+}
+
+// FilterCandidates embeds the snippets of the candidates the filter applies
+// to and returns the votes, indexed like matches; other candidates get no vote.
+func (c *Client) FilterCandidates(ctx context.Context, matches []models.Match) ([]*Vote, error) {
+	votes := make([]*Vote, len(matches))
+	if c.Filter == nil {
+		return votes, nil
+	}
+
+	var idx []int
+	var batch []string
+	for i, m := range matches {
+		if !c.Filter.Applies(m) {
+			continue
+		}
+		text := m.Snippet
+		if text == "" {
+			text = m.Value
+		}
+		idx = append(idx, i)
+		batch = append(batch, text)
+	}
+	if len(batch) == 0 {
+		return votes, nil
+	}
+
+	vectors, err := c.Embed(ctx, c.Filter.Model, batch)
+	if err != nil {
+		return votes, err
+	}
+	for j, i := range idx {
+		v := c.Filter.Vote(matches[i].Type, vectors[j])
+		votes[i] = &v
+		if v.Drop {
+			atomic.AddInt64(&c.stats.Filtered, 1)
+		}
+	}
+	return votes, nil
+}
+
+// Embed returns one vector per text, from the cache where possible. The
+// request goes to the backend directly, like Diagnose.
+func (c *Client) Embed(ctx context.Context, model string, texts []string) ([][]float64, error) {
+	embedder, ok := c.backend().(Embedder)
+	if !ok {
+		return nil, fmt.Errorf("%s does not support embeddings", c.Provider.Name())
+	}
+
+	vectors := make([][]float64, len(texts))
+	var missing []int
+	for i, text := range texts {
+		if c.Embeddings != nil {
+			if v, ok := c.Embeddings.Get(embeddingKey(model, text)); ok {
+				vectors[i] = v
+				continue
+			}
+		}
+		missing = append(missing, i)
+	}
+
+	for start := 0; start < len(missing); start += embedBatch {
+		end := start + embedBatch
+		if end > len(missing) {
+			end = len(missing)
+		}
+		batch := make([]string, 0, end-start)
+		for _, i := range missing[start:end] {
+			batch = append(batch, texts[i])
+		}
+
+		atomic.AddInt64(&c.stats.EmbedRequests, 1)
+		out, err := embedder.Embed(ctx, model, batch)
+		if err != nil {
+			return nil, err
+		}
+		// Never cache a vector that was not returned
+		if len(out) != len(batch) {
+			return nil, fmt.Errorf("%s: %d embeddings for %d texts", c.Provider.Name(), len(out), len(batch))
+		}
+		for j, v := range out {
+			if len(v) == 0 {
+				return nil, fmt.Errorf("%s: no embedding for input %d", c.Provider.Name(), j)
+			}
+		}
+		for j, i := range missing[start:end] {
+			vectors[i] = out[j]
+			if c.Embeddings != nil {
+				c.Embeddings.Put(embeddingKey(model, texts[i]), out[j])
+			}
+		}
+	}
+	return vectors, nil
+}
+
+// embeddingKey identifies one vector: hash(model, text)
+func embeddingKey(model, text string) string {
+	h := sha256.Sum256([]byte(model + "\x00" + text))
+	return hex.EncodeToString(h[:])
+}
+
+func cosine(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
@@ -0,0 +1,116 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/config"
+	"github.com/digimosa/ai-gdpr-scan/internal/models"
+)
+
+// fakeEmbedder embeds texts mentioning an invoice number apart from all
+// others; vectors can replace the answer
+type fakeEmbedder struct {
+	FakeProvider
+	vectors func(texts []string) [][]float64
+
+	mu       sync.Mutex
+	embedded []string
+}
+
+func (p *fakeEmbedder) Embed(ctx context.Context, model string, texts []string) ([][]float64, error) {
+	p.mu.Lock()
+	p.embedded = append(p.embedded, texts...)
+	p.mu.Unlock()
+	if p.vectors != nil {
+		return p.vectors(texts), nil
+	}
+	out := make([][]float64, len(texts))
+	for i, text := range texts {
+		out[i] = []float64{0, 1}
+		if strings.Contains(text, "Rechnungsnr") {
+			out[i] = []float64{1, 0}
+		}
+	}
+	return out, nil
+}
+
+type mapCache map[string][]float64
+
+func (c mapCache) Get(key string) ([]float64, bool) { v, ok := c[key]; return v, ok }
+func (c mapCache) Put(key string, vector []float64) { c[key] = vector }
+
+func newEmbedClient(t *testing.T, embedder *fakeEmbedder) (*Client, *config.Config) {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	cfg.AIModel, cfg.AIAuditLog = "fake", ""
+	cfg.AIEmbedTypes = []string{"Phone"}
+	cfg.AIEmbedMinSimilarity = 0.9
+	return NewClientWithProvider(cfg, embedder), cfg
+}
+
+func TestFilterCandidatesEmbedsSnippets(t *testing.T) {
+	embedder := &fakeEmbedder{}
+	client, cfg := newEmbedClient(t, embedder)
+
+	var examples []Example
+	for _, text := range []string{"Rechnungsnr 0301 234", "Rechnungsnr 0402 555", "Rechnungsnr 0170 991"} {
+		examples = append(examples, Example{Type: models.TypePhone, Text: text})
+	}
+	filter, err := client.NewFPFilter(context.Background(), cfg, examples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Filter = filter
+
+	matches := []models.Match{
+		{Type: models.TypePhone, Value: "0301 777", Snippet: "Rechnungsnr 0301 777 vom"},
+		{Type: models.TypePhone, Value: "0301 777", Snippet: "Tel. 0301 777 erreichbar"},
+		{Type: models.TypeEmail, Value: "max@example.com", Snippet: "Mail max@example.com"},
+	}
+	votes, err := client.FilterCandidates(context.Background(), matches)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if votes[0] == nil || !votes[0].Drop {
+		t.Errorf("vote on the invoice number = %+v, want a drop", votes[0])
+	}
+	if votes[1] == nil || votes[1].Drop {
+		t.Errorf("vote on the phone number = %+v, want it kept", votes[1])
+	}
+	if votes[2] != nil {
+		t.Errorf("vote on the email = %+v, want none", votes[2])
+	}
+
+	candidates := embedder.embedded[len(examples):]
+	if strings.Join(candidates, "|") != "Rechnungsnr 0301 777 vom|Tel. 0301 777 erreichbar" {
+		t.Errorf("embedded %q, want the snippets", candidates)
+	}
+}
+
+func TestEmbedRejectsIncompleteAnswers(t *testing.T) {
+	tests := map[string]func(texts []string) [][]float64{
+		"too few": func(texts []string) [][]float64 {
+			return [][]float64{{1, 0}}
+		},
+		"missing vector": func(texts []string) [][]float64 {
+			return make([][]float64, len(texts))
+		},
+	}
+	for name, vectors := range tests {
+		t.Run(name, func(t *testing.T) {
+			client, _ := newEmbedClient(t, &fakeEmbedder{vectors: vectors})
+			cache := mapCache{}
+			client.Embeddings = cache
+
+			if _, err := client.Embed(context.Background(), "embed", []string{"a", "b"}); err == nil {
+				t.Error("expected an error")
+			}
+			if len(cache) != 0 {
+				t.Errorf("%d vectors cached", len(cache))
+			}
+		})
+	}
+}
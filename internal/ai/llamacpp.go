@@ -0,0 +1,86 @@
+package ai
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// LlamaCppProvider talks to the native /completion endpoint of the llama.cpp server
+type LlamaCppProvider struct {
+	BaseURL string // e.g. http://localhost:8080
+	Client  *http.Client
+}
+
+type llamaCppRequest struct {
+	Prompt     string      `json:"prompt"`
+	NPredict   int         `json:"n_predict"`
+	Stream     bool        `json:"stream"`
+	JSONSchema interface{} `json:"json_schema,omitempty"` // A map, so an empty schema is still sent
+}
+
+type llamaCppResponse struct {
+	Content         string `json:"content"`
+	TokensEvaluated int    `json:"tokens_evaluated"`
+	TokensPredicted int    `json:"tokens_predicted"`
+}
+
+func (p *LlamaCppProvider) Name() string {
+	return ProviderLlamaCpp
+}
+
+// Chat flattens the conversation into a plain prompt. The server applies no
+// chat template on /completion and serves whatever model it was started with,
+// so req.Model is ignored.
+func (p *LlamaCppProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	var sb strings.Builder
+	for _, m := range req.Messages {
+		sb.WriteString(speaker(m.Role))
+		sb.WriteString(": ")
+		sb.WriteString(m.Content)
+		sb.WriteString("\n\n")
+	}
+	sb.WriteString("Assistant: ")
+
+	body := llamaCppRequest{
+		Prompt:   sb.String(),
+		NPredict: 2048,
+	}
+	if req.Schema != nil {
+		body.JSONSchema = req.Schema
+	} else if req.JSON {
+		// An empty schema constrains the output to any valid JSON
+		body.JSONSchema = map[string]interface{}{}
+	}
+
+	var out llamaCppResponse
+	if err := postJSON(ctx, p.Client, p.BaseURL+"/completion", nil, body, &out); err != nil {
+		return nil, err
+	}
+
+	return &ChatResponse{
+		Content:          out.Content,
+		PromptTokens:     out.TokensEvaluated,
+		CompletionTokens: out.TokensPredicted,
+	}, nil
+}
+
+// Embed returns one vector per text from the server's OpenAI-compatible
+// /v1/embeddings endpoint, which llama-server only offers when started with
+// --embeddings. Like Chat it uses the loaded model and ignores model.
+func (p *LlamaCppProvider) Embed(ctx context.Context, model string, texts []string) ([][]float64, error) {
+	return embedOpenAI(ctx, p.Client, p.BaseURL, nil, ProviderLlamaCpp, model, texts)
+}
+
+// speaker labels a message in the flattened prompt; unknown and empty roles
+// are the user's
+func speaker(role string) string {
+	switch role {
+	case "system":
+		return "System"
+	case "assistant":
+		return "Assistant"
+	default:
+		return "User"
+	}
+}
@@ -0,0 +1,92 @@
+package ai
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/models"
+)
+
+// ParseModelCosts reads prices per 1000 tokens given as model=price pairs,
+// e.g. "gpt-4o-mini=0.0006". Prices must be non-negative numbers.
+func ParseModelCosts(pairs []string) (map[string]float64, error) {
+	costs := make(map[string]float64)
+	for _, pair := range pairs {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		model, price, ok := strings.Cut(pair, "=")
+		model = strings.TrimSpace(model)
+		if !ok || model == "" {
+			return nil, fmt.Errorf("%q is not model=price", strings.TrimSpace(pair))
+		}
+		cost, err := strconv.ParseFloat(strings.TrimSpace(price), 64)
+		if err != nil || cost < 0 {
+			return nil, fmt.Errorf("invalid price %q for model %s", strings.TrimSpace(price), model)
+		}
+		costs[model] = cost
+	}
+	return costs, nil
+}
+
+// modelStats tracks request counts, tokens and latency per model
+type modelStats struct {
+	mu     sync.Mutex
+	models map[string]*models.ModelStats
+	costs  map[string]float64 // Price per 1000 tokens by model
+}
+
+func (s *modelStats) get(model string) *models.ModelStats {
+	if s.models == nil {
+		s.models = make(map[string]*models.ModelStats)
+	}
+	st, ok := s.models[model]
+	if !ok {
+		st = &models.ModelStats{Model: model}
+		s.models[model] = st
+	}
+	return st
+}
+
+// record adds one request to the model's statistics
+func (s *modelStats) record(model string, latency time.Duration, resp *ChatResponse, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := s.get(model)
+	st.Requests++
+	st.LatencyTotalMs += latency.Milliseconds()
+	if ms := latency.Milliseconds(); ms > st.LatencyMaxMs {
+		st.LatencyMaxMs = ms
+	}
+	if err != nil {
+		st.Failures++
+		return
+	}
+	st.PromptTokens += int64(resp.PromptTokens)
+	st.CompletionTokens += int64(resp.CompletionTokens)
+	st.Cost = float64(st.PromptTokens+st.CompletionTokens) / 1000 * s.costs[model]
+}
+
+func (s *modelStats) escalated(model string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.get(model).Escalations++
+}
+
+// snapshot returns a copy of the statistics ordered by model name
+func (s *modelStats) snapshot() []models.ModelStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]models.ModelStats, 0, len(s.models))
+	for _, st := range s.models {
+		out = append(out, *st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Model < out[j].Model })
+	return out
+}
@@ -0,0 +1,166 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// OpenAIProvider talks to any server implementing /v1/chat/completions,
+// e.g. vLLM, LM Studio or LocalAI
+type OpenAIProvider struct {
+	BaseURL string // e.g. http://localhost:8000
+	APIKey  string // Optional, sent as Bearer token
+	Client  *http.Client
+}
+
+type openAIChatRequest struct {
+	Model          string          `json:"model"`
+	Messages       []Message       `json:"messages"`
+	Stream         bool            `json:"stream"`
+	ResponseFormat *openAIResponse `json:"response_format,omitempty"`
+}
+
+type openAIResponse struct {
+	Type       string            `json:"type"`
+	JSONSchema *openAIJSONSchema `json:"json_schema,omitempty"`
+}
+
+type openAIJSONSchema struct {
+	Name   string                 `json:"name"`
+	Schema map[string]interface{} `json:"schema"`
+	Strict bool                   `json:"strict"`
+}
+
+type openAIChatResponse struct {
+	Choices []struct {
+		Message Message `json:"message"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+func (p *OpenAIProvider) Name() string {
+	return ProviderOpenAI
+}
+
+func (p *OpenAIProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	body := openAIChatRequest{
+		Model:    req.Model,
+		Messages: req.Messages,
+	}
+	if req.Schema != nil {
+		body.ResponseFormat = &openAIResponse{
+			Type: "json_schema",
+			// Strict mode rejects schemas with minLength, minimum or maximum;
+			// answers are validated and repaired locally anyway
+			JSONSchema: &openAIJSONSchema{Name: "response", Schema: req.Schema},
+		}
+	} else if req.JSON {
+		body.ResponseFormat = &openAIResponse{Type: "json_object"}
+	}
+
+	headers := map[string]string{}
+	if p.APIKey != "" {
+		headers["Authorization"] = "Bearer " + p.APIKey
+	}
+
+	var out openAIChatResponse
+	if err := postJSON(ctx, p.Client, p.BaseURL+"/v1/chat/completions", headers, body, &out); err != nil {
+		return nil, err
+	}
+	if len(out.Choices) == 0 {
+		return nil, errors.New("openai: response contains no choices")
+	}
+
+	return &ChatResponse{
+		Content:          out.Choices[0].Message.Content,
+		PromptTokens:     out.Usage.PromptTokens,
+		CompletionTokens: out.Usage.CompletionTokens,
+	}, nil
+}
+
+type openAIModelsResponse struct {
+	Data []struct {
+		ID          string `json:"id"`
+		MaxModelLen int    `json:"max_model_len"` // vLLM only
+	} `json:"data"`
+}
+
+// ListModels returns the models served (/v1/models). Only vLLM reports a
+// context window; no server reports the quantization.
+func (p *OpenAIProvider) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	headers := map[string]string{}
+	if p.APIKey != "" {
+		headers["Authorization"] = "Bearer " + p.APIKey
+	}
+
+	var out openAIModelsResponse
+	if err := getJSON(ctx, p.Client, p.BaseURL+"/v1/models", headers, &out); err != nil {
+		return nil, err
+	}
+
+	infos := make([]ModelInfo, 0, len(out.Data))
+	for _, m := range out.Data {
+		infos = append(infos, ModelInfo{Name: m.ID, ContextLength: m.MaxModelLen})
+	}
+	return infos, nil
+}
+
+// ShowModel looks the model up in the model list
+func (p *OpenAIProvider) ShowModel(ctx context.Context, name string) (*ModelInfo, error) {
+	infos, err := p.ListModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, info := range infos {
+		if info.Name == name {
+			return &info, nil
+		}
+	}
+	return nil, fmt.Errorf("model %q not found", name)
+}
+
+// Embed returns one vector per text (/v1/embeddings)
+func (p *OpenAIProvider) Embed(ctx context.Context, model string, texts []string) ([][]float64, error) {
+	headers := map[string]string{}
+	if p.APIKey != "" {
+		headers["Authorization"] = "Bearer " + p.APIKey
+	}
+	return embedOpenAI(ctx, p.Client, p.BaseURL, headers, ProviderOpenAI, model, texts)
+}
+
+// embedOpenAI posts texts to an OpenAI-compatible /v1/embeddings endpoint
+// below baseURL and returns the vectors in input order
+func embedOpenAI(ctx context.Context, client *http.Client, baseURL string, headers map[string]string, name, model string, texts []string) ([][]float64, error) {
+	var out struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+	}
+	body := map[string]interface{}{"model": model, "input": texts}
+	if err := postJSON(ctx, client, baseURL+"/v1/embeddings", headers, body, &out); err != nil {
+		return nil, err
+	}
+
+	if len(out.Data) != len(texts) {
+		return nil, fmt.Errorf("%s: %d embeddings for %d texts", name, len(out.Data), len(texts))
+	}
+	vectors := make([][]float64, len(texts))
+	for _, d := range out.Data {
+		if d.Index < 0 || d.Index >= len(vectors) || vectors[d.Index] != nil {
+			return nil, fmt.Errorf("%s: unexpected embedding index %d", name, d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	for i, v := range vectors {
+		if v == nil {
+			return nil, fmt.Errorf("%s: no embedding for input %d", name, i)
+		}
+	}
+	return vectors, nil
+}
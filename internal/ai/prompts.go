@@ -15,9 +15,11 @@ var PromptTemplates = map[models.FindingType]string{
 		- Flag it if it relates to a specific individual.
 	`,
 	models.TypePhone: `
-		- Verify if this is a valid phone number format.
-		- Distinguish between personal mobile numbers and general company hotlines.
-		- Flag personal mobile numbers as high risk.
+		- The number was already validated against the national numbering plan.
+		- Use the Subtype (mobile, landline, toll_free, service) given for each match.
+		- Mobile numbers almost always belong to an individual: flag them as high risk.
+		- Toll-free and service numbers are company hotlines: only flag them if linked to a person.
+		- For landlines, decide from context whether it is a private or a business line.
 	`,
 	models.TypeCreditCard: `
 		- Verify if this number looks like a credit card (13-19 digits).
@@ -26,7 +28,7 @@ var PromptTemplates = map[models.FindingType]string{
 	`,
 	models.TypeName: `
 		- STRICTLY IDENTIFY REAL HUMAN NAMES.
-		- The regex matches capitalized words, but you must filter false positives.
+		- Candidates were pre-filtered with given-name/surname dictionaries and honorifics (Herr, Frau, Dr.), but you must still filter false positives.
 		- REJECT: Company names (GmbH, Inc, Ltd), products, cities, software terms (User, Admin, ID).
 		- ACCEPT: Full names like "John Smith", "Maria Garcia", "Thomas Mueller".
 		- If the text is just a single word that could be a common noun, REJECT it.
@@ -41,8 +43,11 @@ var PromptTemplates = map[models.FindingType]string{
 		- Determine if this data relates to a person's finances.
 	`,
 	models.TypeSensitive: `
-		- CRITICAL: Check for Article 9 GDPR special categories (Health, Religion, Political, Criminal).
-		- Flag immediately if this contains medical diagnoses, political affiliation, or religious beliefs.
+		- CRITICAL: Check for Article 9 GDPR special categories.
+		- Each match carries its category as Subtype: health, religion, politics, union, sexual_orientation, biometric, genetic.
+		- health: ICD-10/OPS codes and medication names. Flag them if they relate to an identifiable person (patient, employee), not in a code catalogue or package insert.
+		- religion/politics/union: flag statements about a person's denomination, party or trade-union membership, not general mentions.
+		- Return the category in the "reason" field.
 	`,
 	models.TypeID: `
 		- Check for official ID numbers (Passport, SSN, Driver's License).
@@ -0,0 +1,154 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/config"
+)
+
+// Supported values for config.AIProvider
+const (
+	ProviderOllama   = "ollama"
+	ProviderOpenAI   = "openai" // Any OpenAI-compatible server: vLLM, LM Studio, LocalAI
+	ProviderLlamaCpp = "llamacpp"
+)
+
+// Message is a single chat turn
+type Message struct {
+	Role    string `json:"role"` // "system", "user" or "assistant"
+	Content string `json:"content"`
+}
+
+// ChatRequest is the provider independent request for one completion
+type ChatRequest struct {
+	Model    string
+	Messages []Message
+	JSON     bool                   // Ask the backend to constrain the output to JSON
+	Schema   map[string]interface{} // Optional JSON schema the output must follow (implies JSON)
+}
+
+// ChatResponse is the provider independent result of one completion
+type ChatResponse struct {
+	Content          string
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// Provider is an LLM backend the analysis pipeline can talk to
+type Provider interface {
+	// Name identifies the backend in logs and reports
+	Name() string
+	// Chat runs a single non-streaming completion
+	Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error)
+}
+
+// Embedder is implemented by providers that can embed text, see FPFilter
+type Embedder interface {
+	Embed(ctx context.Context, model string, texts []string) ([][]float64, error)
+}
+
+// ModelInfo describes a model installed on the backend. Fields the backend
+// does not report are left empty.
+type ModelInfo struct {
+	Name          string
+	Family        string // e.g. "llama"
+	ParameterSize string // e.g. "3.2B"
+	Quantization  string // e.g. "Q4_K_M"
+	ContextLength int    // Trained context window in tokens
+	NumCtx        int    // Context window the server runs the model with, if configured
+	Size          int64  // Bytes on disk
+}
+
+// ModelInspector is implemented by providers that can list and describe
+// their models, see Client.Diagnose
+type ModelInspector interface {
+	ListModels(ctx context.Context) ([]ModelInfo, error)
+	ShowModel(ctx context.Context, name string) (*ModelInfo, error)
+}
+
+// NewProvider creates the backend selected by cfg.AIProvider
+func NewProvider(cfg *config.Config) (Provider, error) {
+	timeout := cfg.AITimeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	httpClient := &http.Client{Timeout: timeout}
+	baseURL := strings.TrimRight(cfg.AIURL, "/")
+
+	switch strings.ToLower(cfg.AIProvider) {
+	case "", ProviderOllama:
+		return &OllamaProvider{BaseURL: baseURL, Client: httpClient}, nil
+	case ProviderOpenAI:
+		return &OpenAIProvider{BaseURL: baseURL, APIKey: cfg.AIAPIKey, Client: httpClient}, nil
+	case ProviderLlamaCpp:
+		return &LlamaCppProvider{BaseURL: baseURL, Client: httpClient}, nil
+	default:
+		return nil, fmt.Errorf("unknown AI provider %q (supported: %s, %s, %s)", cfg.AIProvider, ProviderOllama, ProviderOpenAI, ProviderLlamaCpp)
+	}
+}
+
+// HTTPError is returned when a backend answers with a non-200 status
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("AI backend returned status %d: %s", e.StatusCode, e.Body)
+}
+
+// postJSON sends body as JSON and decodes a 200 response into out
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, body, out interface{}) error {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	return doJSON(client, req, out)
+}
+
+// getJSON fetches url and decodes a 200 response into out
+func getJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return doJSON(client, req, out)
+}
+
+func doJSON(client *http.Client, req *http.Request, out interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &HTTPError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(msg))}
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// defaultTimeout is used when the config does not set one
+const defaultTimeout = 60 * time.Second
@@ -0,0 +1,202 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/config"
+)
+
+// captured is one request received by a backend stub
+type captured struct {
+	Path   string
+	Header http.Header
+	Body   map[string]interface{}
+}
+
+// stubBackend answers every request with reply and records what it received
+func stubBackend(t *testing.T, reply string) (*httptest.Server, *[]captured) {
+	t.Helper()
+	var requests []captured
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		c := captured{Path: r.URL.Path, Header: r.Header.Clone()}
+		if err := json.Unmarshal(data, &c.Body); err != nil {
+			t.Errorf("request body is not JSON: %v", err)
+		}
+		requests = append(requests, c)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, reply)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func newTestProvider(t *testing.T, name, url string) Provider {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	cfg.AIProvider, cfg.AIURL, cfg.AIAPIKey = name, url+"/", "secret"
+	p, err := NewProvider(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+var testMessages = []Message{
+	{Role: "system", Content: "Be strict."},
+	{Role: "user", Content: "Find PII."},
+}
+
+func TestOllamaRequest(t *testing.T) {
+	srv, requests := stubBackend(t, `{"message": {"role": "assistant", "content": "{}"}, "done": true, "prompt_eval_count": 12, "eval_count": 3}`)
+	p := newTestProvider(t, ProviderOllama, srv.URL)
+
+	resp, err := p.Chat(context.Background(), ChatRequest{Model: "llama3.2", Messages: testMessages, Schema: FindingsSchema})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "{}" || resp.PromptTokens != 12 || resp.CompletionTokens != 3 {
+		t.Errorf("response = %+v", resp)
+	}
+
+	got := (*requests)[0]
+	if got.Path != "/api/chat" {
+		t.Errorf("path = %s", got.Path)
+	}
+	if got.Body["model"] != "llama3.2" || got.Body["stream"] != false {
+		t.Errorf("body = %v", got.Body)
+	}
+	if format, ok := got.Body["format"].(map[string]interface{}); !ok || format["type"] != "object" {
+		t.Errorf("format = %v, want the findings schema", got.Body["format"])
+	}
+	if messages := got.Body["messages"].([]interface{}); len(messages) != 2 {
+		t.Errorf("messages = %v", messages)
+	}
+
+	// Without a schema JSON mode is requested by name
+	p.Chat(context.Background(), ChatRequest{Model: "llama3.2", Messages: testMessages, JSON: true})
+	if format := (*requests)[1].Body["format"]; format != "json" {
+		t.Errorf("format = %v, want json", format)
+	}
+}
+
+func TestOpenAIRequest(t *testing.T) {
+	srv, requests := stubBackend(t, `{"choices": [{"message": {"role": "assistant", "content": "{}"}}], "usage": {"prompt_tokens": 20, "completion_tokens": 4}}`)
+	p := newTestProvider(t, ProviderOpenAI, srv.URL)
+
+	resp, err := p.Chat(context.Background(), ChatRequest{Model: "qwen2.5", Messages: testMessages, Schema: FindingsSchema})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "{}" || resp.PromptTokens != 20 || resp.CompletionTokens != 4 {
+		t.Errorf("response = %+v", resp)
+	}
+
+	got := (*requests)[0]
+	if got.Path != "/v1/chat/completions" {
+		t.Errorf("path = %s", got.Path)
+	}
+	if auth := got.Header.Get("Authorization"); auth != "Bearer secret" {
+		t.Errorf("Authorization = %q", auth)
+	}
+	format, _ := got.Body["response_format"].(map[string]interface{})
+	schema, _ := format["json_schema"].(map[string]interface{})
+	if format["type"] != "json_schema" || schema["strict"] != false || schema["schema"] == nil {
+		t.Errorf("response_format = %v", got.Body["response_format"])
+	}
+
+	p.Chat(context.Background(), ChatRequest{Model: "qwen2.5", Messages: testMessages, JSON: true})
+	format, _ = (*requests)[1].Body["response_format"].(map[string]interface{})
+	if format["type"] != "json_object" {
+		t.Errorf("response_format = %v, want json_object", format)
+	}
+
+	p.Chat(context.Background(), ChatRequest{Model: "qwen2.5", Messages: testMessages})
+	if _, ok := (*requests)[2].Body["response_format"]; ok {
+		t.Error("plain requests must not set response_format")
+	}
+}
+
+func TestLlamaCppRequest(t *testing.T) {
+	srv, requests := stubBackend(t, `{"content": "{}", "tokens_evaluated": 30, "tokens_predicted": 2}`)
+	p := newTestProvider(t, ProviderLlamaCpp, srv.URL)
+
+	messages := append(testMessages, Message{Role: "assistant", Content: "{}"}, Message{Content: "Again."})
+	resp, err := p.Chat(context.Background(), ChatRequest{Model: "ignored", Messages: messages, Schema: FindingsSchema})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "{}" || resp.PromptTokens != 30 || resp.CompletionTokens != 2 {
+		t.Errorf("response = %+v", resp)
+	}
+
+	got := (*requests)[0]
+	if got.Path != "/completion" {
+		t.Errorf("path = %s", got.Path)
+	}
+	prompt, _ := got.Body["prompt"].(string)
+	want := "System: Be strict.\n\nUser: Find PII.\n\nAssistant: {}\n\nUser: Again.\n\nAssistant: "
+	if prompt != want {
+		t.Errorf("prompt = %q, want %q", prompt, want)
+	}
+	if schema, ok := got.Body["json_schema"].(map[string]interface{}); !ok || schema["type"] != "object" {
+		t.Errorf("json_schema = %v", got.Body["json_schema"])
+	}
+	if _, ok := got.Body["model"]; ok {
+		t.Error("llama.cpp requests carry no model")
+	}
+
+	p.Chat(context.Background(), ChatRequest{Messages: testMessages, JSON: true})
+	if schema, ok := (*requests)[1].Body["json_schema"].(map[string]interface{}); !ok || len(schema) != 0 {
+		t.Errorf("json_schema = %v, want an empty schema", (*requests)[1].Body["json_schema"])
+	}
+}
+
+func TestLlamaCppEmbed(t *testing.T) {
+	srv, requests := stubBackend(t, `{"data": [{"index": 1, "embedding": [0, 1]}, {"index": 0, "embedding": [1, 0]}]}`)
+	p := newTestProvider(t, ProviderLlamaCpp, srv.URL)
+
+	vectors, err := p.(Embedder).Embed(context.Background(), "ignored", []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vectors) != 2 || vectors[0][0] != 1 || vectors[1][1] != 1 {
+		t.Errorf("vectors = %v, want them in input order", vectors)
+	}
+	if got := (*requests)[0]; got.Path != "/v1/embeddings" || len(got.Body["input"].([]interface{})) != 2 {
+		t.Errorf("request %s %v", got.Path, got.Body)
+	}
+
+	// An answer that skips an input is an error
+	srv, _ = stubBackend(t, `{"data": [{"index": 0, "embedding": [1, 0]}, {"index": 0, "embedding": [1, 0]}]}`)
+	if _, err := newTestProvider(t, ProviderLlamaCpp, srv.URL).(Embedder).Embed(context.Background(), "", []string{"a", "b"}); err == nil {
+		t.Error("expected an error for a duplicate index")
+	}
+}
+
+func TestProviderHTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not loaded", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	_, err := newTestProvider(t, ProviderOllama, srv.URL).Chat(context.Background(), ChatRequest{Messages: testMessages})
+	httpErr, ok := err.(*HTTPError)
+	if !ok || httpErr.StatusCode != http.StatusServiceUnavailable || !strings.Contains(httpErr.Body, "model not loaded") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestNewProviderRejectsUnknownBackends(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.AIProvider = "bard"
+	if _, err := NewProvider(cfg); err == nil {
+		t.Error("expected an error")
+	}
+}
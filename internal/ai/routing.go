@@ -0,0 +1,131 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/config"
+	"github.com/digimosa/ai-gdpr-scan/internal/models"
+)
+
+// Router applies the routing policy: which finding types are reported
+// without a model, which go to the small default model and which to the
+// large one, and when a small model answer is re-checked by the large model
+type Router struct {
+	SmallModel string
+	LargeModel string // Empty: every reviewed type goes to SmallModel
+
+	// Small model findings with EscalateMin <= confidence < EscalateMax are
+	// uncertain; their chunk is sent to LargeModel again
+	EscalateMin float64
+	EscalateMax float64
+
+	skip  map[models.FindingType]bool
+	large map[models.FindingType]bool
+}
+
+// RouteGroup is a set of candidates reviewed together by one model
+type RouteGroup struct {
+	Model   string
+	Matches []models.Match
+}
+
+// NewRouter builds the policy configured in cfg
+func NewRouter(cfg *config.Config) *Router {
+	r := &Router{
+		SmallModel:  cfg.AIModel,
+		LargeModel:  cfg.AILargeModel,
+		EscalateMin: cfg.AIEscalateMin,
+		EscalateMax: cfg.AIEscalateMax,
+		skip:        typeSet(cfg.AISkipTypes),
+		large:       typeSet(cfg.AILargeTypes),
+	}
+	if r.LargeModel == r.SmallModel {
+		r.LargeModel = ""
+	}
+	return r
+}
+
+// ParseTypes returns the canonical names of the finding types in names,
+// matched case-insensitively, e.g. "iban" is "IBAN". Unknown names are an
+// error.
+func ParseTypes(names []string) ([]string, error) {
+	var out []string
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		t, ok := canonicalType(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown finding type %q (supported: %s)", strings.TrimSpace(name), strings.Join(analysisTypeNames(), ", "))
+		}
+		out = append(out, string(t))
+	}
+	return out, nil
+}
+
+// typeSet holds the known types among names; see ParseTypes
+func typeSet(names []string) map[models.FindingType]bool {
+	set := make(map[models.FindingType]bool)
+	for _, name := range names {
+		if t, ok := canonicalType(name); ok {
+			set[t] = true
+		}
+	}
+	return set
+}
+
+// Skip separates the matches whose type is reported without a model
+func (r *Router) Skip(matches []models.Match) (reviewed, skipped []models.Match) {
+	for _, m := range matches {
+		if r.skip[m.Type] {
+			skipped = append(skipped, m)
+		} else {
+			reviewed = append(reviewed, m)
+		}
+	}
+	return reviewed, skipped
+}
+
+// Split groups the candidates of a chunk by the model that reviews them,
+// small model first
+func (r *Router) Split(matches []models.Match) []RouteGroup {
+	if len(matches) == 0 {
+		return nil
+	}
+	if r.LargeModel == "" {
+		return []RouteGroup{{Model: r.SmallModel, Matches: matches}}
+	}
+
+	small := RouteGroup{Model: r.SmallModel}
+	large := RouteGroup{Model: r.LargeModel}
+	for _, m := range matches {
+		if r.large[m.Type] {
+			large.Matches = append(large.Matches, m)
+		} else {
+			small.Matches = append(small.Matches, m)
+		}
+	}
+
+	var groups []RouteGroup
+	for _, g := range []RouteGroup{small, large} {
+		if len(g.Matches) > 0 {
+			groups = append(groups, g)
+		}
+	}
+	return groups
+}
+
+// Escalate reports whether an answer of model is uncertain enough to ask
+// the large model
+func (r *Router) Escalate(model string, findings []FindingResult) bool {
+	if r.LargeModel == "" || model == r.LargeModel {
+		return false
+	}
+	for _, f := range findings {
+		if f.Confidence >= r.EscalateMin && f.Confidence < r.EscalateMax {
+			return true
+		}
+	}
+	return false
+}
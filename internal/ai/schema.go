@@ -0,0 +1,150 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/models"
+)
+
+// AnalysisTypes are the finding types the model may report. Credentials are
+// never sent to the model and therefore not listed.
+var AnalysisTypes = []models.FindingType{
+	models.TypeIBAN,
+	models.TypeEmail,
+	models.TypePhone,
+	models.TypeName,
+	models.TypeIdentity,
+	models.TypeFinancial,
+	models.TypeID,
+	models.TypeSensitive,
+	models.TypeCreditCard,
+}
+
+// FindingsSchema is the JSON schema every analysis response must follow.
+// It is passed to backends that support constrained decoding (Ollama
+// "format", OpenAI "response_format", llama.cpp "json_schema").
+var FindingsSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"findings": map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"type":       map[string]interface{}{"type": "string", "enum": analysisTypeNames()},
+					"value":      map[string]interface{}{"type": "string", "minLength": 1},
+					"reason":     map[string]interface{}{"type": "string"},
+					"confidence": map[string]interface{}{"type": "number", "minimum": 0, "maximum": 1},
+				},
+				"required":             []string{"type", "value", "reason", "confidence"},
+				"additionalProperties": false,
+			},
+		},
+	},
+	"required":             []string{"findings"},
+	"additionalProperties": false,
+}
+
+func analysisTypeNames() []string {
+	names := make([]string, len(AnalysisTypes))
+	for i, t := range AnalysisTypes {
+		names[i] = string(t)
+	}
+	return names
+}
+
+// ValidationError lists why a response does not match FindingsSchema
+type ValidationError struct {
+	Problems []string
+	NotJSON  bool // The response could not be decoded at all
+	Invalid  int  // Findings failing validation; 0 when the response as a whole is unusable
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid AI response: " + strings.Join(e.Problems, "; ")
+}
+
+// IsValidationError reports whether err is caused by an invalid model answer
+// rather than by the backend
+func IsValidationError(err error) bool {
+	var v *ValidationError
+	return errors.As(err, &v)
+}
+
+// parseFindings decodes and validates a response against FindingsSchema.
+// Type names are matched case-insensitively and returned in canonical form.
+// Findings are validated one by one: the valid ones are returned along with
+// a ValidationError listing the others.
+func parseFindings(responseText string) ([]FindingResult, error) {
+	var resp struct {
+		Findings *[]struct {
+			Type       string   `json:"type"`
+			Value      string   `json:"value"`
+			Reason     string   `json:"reason"`
+			Confidence *float64 `json:"confidence"`
+		} `json:"findings"`
+	}
+
+	// Models without constrained decoding like to wrap JSON in markdown fences
+	if err := json.Unmarshal([]byte(cleanMarkdown(responseText)), &resp); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, &ValidationError{Problems: []string{fmt.Sprintf("not valid JSON (%v)", err)}, NotJSON: true}
+		}
+		return nil, &ValidationError{Problems: []string{`expected a JSON object {"findings": [...]} with string type, value and reason and a numeric confidence`}}
+	}
+	if resp.Findings == nil {
+		return nil, &ValidationError{Problems: []string{`missing "findings" array`}}
+	}
+
+	var results []FindingResult
+	var problems []string
+	for i, f := range *resp.Findings {
+		t, ok := canonicalType(f.Type)
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("findings[%d].type %q is not one of %s", i, f.Type, strings.Join(analysisTypeNames(), ", ")))
+		case strings.TrimSpace(f.Value) == "":
+			problems = append(problems, fmt.Sprintf("findings[%d].value is empty", i))
+		case f.Confidence == nil:
+			problems = append(problems, fmt.Sprintf("findings[%d].confidence is missing", i))
+		case *f.Confidence < 0 || *f.Confidence > 1:
+			problems = append(problems, fmt.Sprintf("findings[%d].confidence %v is outside 0.0-1.0", i, *f.Confidence))
+		default:
+			results = append(results, FindingResult{
+				Type:       string(t),
+				Value:      f.Value,
+				Reason:     f.Reason,
+				Confidence: *f.Confidence,
+			})
+		}
+	}
+
+	if len(problems) > 0 {
+		return results, &ValidationError{Problems: problems, Invalid: len(problems)}
+	}
+	return results, nil
+}
+
+func canonicalType(name string) (models.FindingType, bool) {
+	for _, t := range AnalysisTypes {
+		if strings.EqualFold(strings.TrimSpace(name), string(t)) {
+			return t, true
+		}
+	}
+	return "", false
+}
+
+func cleanMarkdown(text string) string {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "```json") {
+		text = strings.TrimPrefix(text, "```json")
+	} else if strings.HasPrefix(text, "```") {
+		text = strings.TrimPrefix(text, "```")
+	}
+	text = strings.TrimSuffix(text, "```")
+	return strings.TrimSpace(text)
+}
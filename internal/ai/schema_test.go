@@ -0,0 +1,77 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseFindings(t *testing.T) {
+	results, err := parseFindings("```json\n" + `{"findings": [
+		{"type": "iban", "value": "DE89370400440532013000", "reason": "labelled", "confidence": 0.95},
+		{"type": "Name", "value": "Max Mustermann", "reason": "salutation", "confidence": 0.8}
+	]}` + "\n```")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d findings, want 2", len(results))
+	}
+	if results[0].Type != "IBAN" {
+		t.Errorf("type = %q, want the canonical IBAN", results[0].Type)
+	}
+	if results[1].Confidence != 0.8 {
+		t.Errorf("confidence = %v", results[1].Confidence)
+	}
+}
+
+func TestParseFindingsKeepsValidItems(t *testing.T) {
+	results, err := parseFindings(`{"findings": [
+		{"type": "Email", "value": "max@example.com", "reason": "", "confidence": 0.9},
+		{"type": "Address", "value": "Hauptstr. 1", "reason": "", "confidence": 0.9},
+		{"type": "Phone", "value": "030 1234567", "reason": "", "confidence": 1.5},
+		{"type": "Name", "value": " ", "reason": "", "confidence": 0.5}
+	]}`)
+	invalid, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("err = %v, want a ValidationError", err)
+	}
+	if invalid.Invalid != 3 || invalid.NotJSON {
+		t.Errorf("invalid = %d, NotJSON = %v; want 3, false", invalid.Invalid, invalid.NotJSON)
+	}
+	if len(results) != 1 || results[0].Value != "max@example.com" {
+		t.Errorf("results = %+v, want only the email", results)
+	}
+	for _, want := range []string{`"Address"`, "outside 0.0-1.0", "value is empty"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+}
+
+func TestParseFindingsRejectsUnusableAnswers(t *testing.T) {
+	tests := []struct {
+		name    string
+		answer  string
+		notJSON bool
+	}{
+		{"not JSON", "Here are the findings: none", true},
+		{"truncated", `{"findings": [{"type": "Email"`, true},
+		{"missing array", `{"results": []}`, false},
+		{"wrong shape", `{"findings": "none"}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := parseFindings(tt.answer)
+			invalid, ok := err.(*ValidationError)
+			if !ok {
+				t.Fatalf("err = %v, want a ValidationError", err)
+			}
+			if results != nil {
+				t.Errorf("results = %+v, want none", results)
+			}
+			if invalid.NotJSON != tt.notJSON || invalid.Invalid != 0 {
+				t.Errorf("NotJSON = %v, Invalid = %d; want %v, 0", invalid.NotJSON, invalid.Invalid, tt.notJSON)
+			}
+		})
+	}
+}
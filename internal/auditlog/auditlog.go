@@ -0,0 +1,228 @@
+// Package auditlog records every AI backend request as one JSON line. Entries
+// carry request metadata, costs and keyed hashes of detected values, never
+// document text, so the log can be kept and shared for auditing.
+package auditlog
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entry statuses
+const (
+	StatusOK     = "ok"
+	StatusCached = "cached" // Answered from the verdict cache, no request sent
+	StatusError  = "error"
+)
+
+// Entry is one model request
+type Entry struct {
+	Time             time.Time `json:"time"`
+	RequestID        string    `json:"request_id"`        // Shared by the attempts of one request
+	Attempt          int       `json:"attempt,omitempty"` // Repairs of an invalid answer count from 1
+	Kind             string    `json:"kind"`              // "chunk", "classify" or "validate"
+	File             string    `json:"file,omitempty"`
+	Provider         string    `json:"provider"`
+	Model            string    `json:"model"`
+	Escalated        bool      `json:"escalated,omitempty"`
+	LatencyMs        int64     `json:"latency_ms"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	PromptHash       string    `json:"prompt_hash"`      // Keyed hash of the prompt
+	Status           string    `json:"status"`           // StatusOK, StatusCached or StatusError
+	Error            string    `json:"error,omitempty"`  // Error class, without backend output
+	Answer           string    `json:"answer,omitempty"` // Answers without personal data, e.g. a category
+	Findings         []Finding `json:"findings,omitempty"`
+}
+
+// Finding is a reported value, identified by its keyed hash
+type Finding struct {
+	Type       string  `json:"type"`
+	ValueHash  string  `json:"value_hash"`
+	Confidence float64 `json:"confidence"`
+}
+
+// Logger appends entries to a file readable by its owner only. The file is
+// rotated to Path.1, Path.2, ... when it exceeds MaxSize bytes or its first
+// entry is older than MaxAge; MaxBackups rotated files are kept.
+type Logger struct {
+	Path       string
+	MaxSize    int64
+	MaxAge     time.Duration
+	MaxBackups int
+
+	mu      sync.Mutex
+	key     []byte
+	file    *os.File
+	size    int64
+	started time.Time // Time of the first entry in the current file
+}
+
+var (
+	loggersMu sync.Mutex
+	loggers   = make(map[string]*Logger)
+)
+
+// Open returns the logger for path. Loggers are shared per path, so every
+// AI client of the process writes through the same rotation state. The
+// hash key is kept in path + ".key" and reused, so equal values hash alike
+// across runs.
+func Open(path string, maxSize int64, maxAge time.Duration, maxBackups int) (*Logger, error) {
+	loggersMu.Lock()
+	defer loggersMu.Unlock()
+	if l, ok := loggers[path]; ok {
+		return l, nil
+	}
+
+	key, err := loadKey(path + ".key")
+	if err != nil {
+		return nil, err
+	}
+	l := &Logger{Path: path, MaxSize: maxSize, MaxAge: maxAge, MaxBackups: maxBackups, key: key}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	loggers[path] = l
+	return l, nil
+}
+
+func loadKey(path string) ([]byte, error) {
+	if data, err := os.ReadFile(path); err == nil {
+		if key, err := hex.DecodeString(strings.TrimSpace(string(data))); err == nil && len(key) >= 16 {
+			return key, nil
+		}
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, []byte(hex.EncodeToString(key)), 0600); err != nil {
+		return nil, fmt.Errorf("could not create audit log key: %v", err)
+	}
+	return key, nil
+}
+
+// open opens the current file for appending and tightens its mode, which
+// O_CREATE does not change for an existing file
+func (l *Logger) open() error {
+	f, err := os.OpenFile(l.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	l.file, l.size = f, info.Size()
+	l.started = time.Now()
+	if first, ok := firstEntry(l.Path); ok {
+		l.started = first.Time
+	}
+	return nil
+}
+
+func firstEntry(path string) (Entry, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Entry{}, false
+	}
+	defer f.Close()
+
+	var e Entry
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), maxLineSize)
+	if !sc.Scan() || json.Unmarshal(sc.Bytes(), &e) != nil {
+		return Entry{}, false
+	}
+	return e, true
+}
+
+// Hash is the keyed hash of a value, short enough to compare by eye
+func (l *Logger) Hash(value string) string {
+	mac := hmac.New(sha256.New, l.key)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))[:16]
+}
+
+// Write appends one entry, rotating the file first if needed
+func (l *Logger) Write(e Entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return os.ErrClosed
+	}
+	tooBig := l.MaxSize > 0 && l.size > 0 && l.size+int64(len(line)) > l.MaxSize
+	tooOld := l.MaxAge > 0 && l.size > 0 && time.Since(l.started) > l.MaxAge
+	if tooBig || tooOld {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+	if l.size == 0 {
+		l.started = e.Time
+	}
+
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+	return err
+}
+
+// rotate shifts Path.i to Path.i+1, dropping the oldest, and starts a new file
+func (l *Logger) rotate() error {
+	l.file.Close()
+	l.file = nil
+
+	os.Remove(backupName(l.Path, l.MaxBackups))
+	for i := l.MaxBackups - 1; i >= 1; i-- {
+		os.Rename(backupName(l.Path, i), backupName(l.Path, i+1))
+	}
+	if l.MaxBackups > 0 {
+		if err := os.Rename(l.Path, backupName(l.Path, 1)); err != nil {
+			return err
+		}
+	} else if err := os.Remove(l.Path); err != nil {
+		return err
+	}
+	return l.open()
+}
+
+func backupName(path string, i int) string {
+	if i == 0 {
+		return path
+	}
+	return fmt.Sprintf("%s.%d", path, i)
+}
+
+// Close closes the current file; later writes fail
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
@@ -0,0 +1,95 @@
+package auditlog
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+)
+
+// maxLineSize bounds one entry when reading the log
+const maxLineSize = 1024 * 1024
+
+// Query selects entries, newest first. Empty fields match everything; File
+// matches a part of the path.
+type Query struct {
+	RequestID string
+	File      string
+	Model     string
+	Kind      string
+	Status    string
+	Since     time.Time
+
+	Offset int
+	Limit  int
+}
+
+// Page is one page of matching entries
+type Page struct {
+	Entries []Entry `json:"entries"`
+	Total   int     `json:"total"` // Matching entries on all pages
+	Offset  int     `json:"offset"`
+	Limit   int     `json:"limit"`
+}
+
+func (q Query) matches(e Entry) bool {
+	return (q.RequestID == "" || e.RequestID == q.RequestID) &&
+		(q.File == "" || strings.Contains(e.File, q.File)) &&
+		(q.Model == "" || e.Model == q.Model) &&
+		(q.Kind == "" || e.Kind == q.Kind) &&
+		(q.Status == "" || e.Status == q.Status) &&
+		(q.Since.IsZero() || !e.Time.Before(q.Since))
+}
+
+// Read returns the entries of the log at path and its maxBackups rotated
+// files that match q. Lines that are not valid entries are skipped.
+func Read(path string, maxBackups int, q Query) (*Page, error) {
+	page := &Page{Entries: []Entry{}, Offset: q.Offset, Limit: q.Limit}
+
+	found := false
+	for i := 0; i <= maxBackups; i++ {
+		entries, err := readFile(backupName(path, i))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found = true
+
+		// Files are read newest first, entries within a file oldest first
+		for j := len(entries) - 1; j >= 0; j-- {
+			if !q.matches(entries[j]) {
+				continue
+			}
+			if page.Total >= q.Offset && (q.Limit <= 0 || len(page.Entries) < q.Limit) {
+				page.Entries = append(page.Entries, entries[j])
+			}
+			page.Total++
+		}
+	}
+	if !found {
+		return nil, os.ErrNotExist
+	}
+	return page, nil
+}
+
+func readFile(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), maxLineSize)
+	for sc.Scan() {
+		var e Entry
+		if json.Unmarshal(sc.Bytes(), &e) == nil {
+			entries = append(entries, e)
+		}
+	}
+	return entries, sc.Err()
+}
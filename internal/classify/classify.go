@@ -0,0 +1,208 @@
+// Package classify assigns a document category such as "CV" or "payslip" to
+// a file. Category profiles combine title and vocabulary keywords with the
+// kinds of values the detectors found; an optional model verdict is merged
+// in by the scanner.
+package classify
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/models"
+)
+
+// maxClassifyText bounds the text inspected; titles and form labels that
+// identify a document type sit near its start
+const maxClassifyText = 64 * 1024
+
+// minScore is the profile score a document needs to be assigned a category:
+// a title word alone is not enough, it takes one more supporting hit
+const minScore = 2.5
+
+// Weights of the profile parts
+const (
+	strongWeight  = 2.0 // Title words that name the document type, counted once each
+	weakWeight    = 0.5 // Vocabulary typical of the document type, counted once each
+	maxWeakScore  = 3.0
+	defaultSignal = 0.5
+)
+
+// Signal is a detector finding type that supports a category
+type Signal struct {
+	Type    models.FindingType
+	Subtype string // Optional, e.g. "health" for Sensitive
+	Weight  float64
+}
+
+// Profile describes one document category
+type Profile struct {
+	Category string
+	Strong   *regexp.Regexp // Titles such as "Lebenslauf" or "Gehaltsabrechnung"
+	Weak     *regexp.Regexp // Vocabulary such as "Berufserfahrung" or "Nettobetrag"
+	Signals  []Signal
+}
+
+// Profiles are the built-in categories, see models.Categories. Alternatives
+// are tried in order, so longer words come before their prefixes.
+var Profiles = []Profile{
+	{
+		Category: models.CategoryCV,
+		Strong:   regexp.MustCompile(`(?i)(Lebenslauf|Curriculum Vitae|Résumé|Bewerbung als|Bewerbungsschreiben|Application for the position)`),
+		Weak:     regexp.MustCompile(`(?i)(Berufserfahrung|Berufliche Laufbahn|Ausbildung|Schulbildung|Studium|Kenntnisse|Sprachkenntnisse|EDV-Kenntnisse|Hobbys|Interessen|Referenzen|Work Experience|Professional Experience|Education|Skills|Languages|Interests|References|Geburtsdatum|Familienstand)`),
+		Signals: []Signal{
+			{Type: models.TypeName, Weight: defaultSignal},
+			{Type: models.TypeEmail, Weight: defaultSignal},
+			{Type: models.TypePhone, Weight: defaultSignal},
+		},
+	},
+	{
+		Category: models.CategoryPayslip,
+		Strong:   regexp.MustCompile(`(?i)(Gehaltsabrechnung|Lohnabrechnung|Entgeltabrechnung|Verdienstabrechnung|Bezügemitteilung|Payslip|Pay slip|Salary statement|Earnings statement)`),
+		Weak:     regexp.MustCompile(`(?i)(Bruttobezüge|Nettobezüge|Brutto|Netto|Auszahlungsbetrag|Lohnsteuer|Solidaritätszuschlag|Kirchensteuer|Steuerklasse|Rentenversicherung|Krankenversicherung|Arbeitslosenversicherung|Pflegeversicherung|Personalnummer|Gross pay|Net pay|Income tax|National Insurance|Deductions|Pay period)`),
+		Signals: []Signal{
+			{Type: models.TypeIBAN, Weight: defaultSignal},
+			{Type: models.TypeID, Weight: defaultSignal},
+			{Type: models.TypeName, Weight: defaultSignal},
+		},
+	},
+	{
+		Category: models.CategoryInvoice,
+		Strong:   regexp.MustCompile(`(?i)(Rechnung Nr|Rechnungsnummer|Rechnungsdatum|Invoice number|Invoice No|Invoice date|Tax invoice|Gutschrift Nr)`),
+		Weak:     regexp.MustCompile(`(?i)(Rechnungsbetrag|Rechnung|Invoice|Zwischensumme|Gesamtbetrag|MwSt|USt-IdNr|USt|Umsatzsteuer|Zahlungsziel|zahlbar bis|Leistungszeitraum|Kundennummer|Subtotal|Total due|Amount due|VAT|Payment terms|Due date)`),
+		Signals: []Signal{
+			{Type: models.TypeIBAN, Weight: defaultSignal},
+			{Type: models.TypeFinancial, Weight: defaultSignal},
+		},
+	},
+	{
+		Category: models.CategoryMedical,
+		Strong:   regexp.MustCompile(`(?i)(Arztbrief|Entlassungsbrief|Entlassbericht|Befundbericht|Arbeitsunfähigkeitsbescheinigung|AU-Bescheinigung|Krankmeldung|Ärztliches Attest|Discharge summary|Medical report|Sick note|Doctor's note)`),
+		Weak:     regexp.MustCompile(`(?i)(Patientin|Patient|Diagnosen|Diagnose|Befund|Anamnese|Therapie|Medikation|Behandlung|Klinik|Praxis|Sehr geehrte Frau Kollegin|Sehr geehrter Herr Kollege|Diagnosis|Treatment|Medication|Prescription|Clinic)`),
+		Signals: []Signal{
+			{Type: models.TypeSensitive, Subtype: "health", Weight: 1.0},
+			{Type: models.TypeName, Weight: defaultSignal},
+		},
+	},
+	{
+		Category: models.CategoryIDCopy,
+		Strong:   regexp.MustCompile(`(?i:Personalausweis|Reisepass|Identity card|Passport|Carte d'identité|Aufenthaltstitel|Führerschein|Driving licen[cs]e)|(?:P<|I<|ID)[A-Z]{3}[A-Z<]{20,}`), // Names and machine readable zones
+		Weak:     regexp.MustCompile(`(?i)(Staatsangehörigkeit|Nationality|Geburtsort|Place of birth|Date of birth|Gültig bis|Date of expiry|Ausstellende Behörde|Authority|Augenfarbe|Größe|Height|Sex|Surname|Given names)`),
+		Signals: []Signal{
+			{Type: models.TypeID, Weight: 1.0},
+			{Type: models.TypeName, Weight: defaultSignal},
+		},
+	},
+}
+
+// Result is the category assigned to a document
+type Result struct {
+	Category   string
+	Confidence float64
+	Scores     map[string]float64 // Profile score per category
+}
+
+// Classifier scores documents against a set of profiles
+type Classifier struct {
+	Profiles []Profile
+}
+
+func NewClassifier() *Classifier {
+	return &Classifier{Profiles: Profiles}
+}
+
+// Classify returns the best matching category, or an empty category when no
+// profile reaches minScore. Confidence drops when a second profile scores
+// almost as high, e.g. a payslip that also reads like an invoice.
+func (c *Classifier) Classify(text string, matches []models.Match) Result {
+	if len(text) > maxClassifyText {
+		text = text[:maxClassifyText]
+	}
+
+	res := Result{Scores: make(map[string]float64)}
+	for _, p := range c.Profiles {
+		res.Scores[p.Category] = p.score(text, matches)
+	}
+
+	ranked := make([]string, 0, len(res.Scores))
+	for category := range res.Scores {
+		ranked = append(ranked, category)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if res.Scores[ranked[i]] != res.Scores[ranked[j]] {
+			return res.Scores[ranked[i]] > res.Scores[ranked[j]]
+		}
+		return ranked[i] < ranked[j]
+	})
+	if len(ranked) == 0 || res.Scores[ranked[0]] < minScore {
+		return res
+	}
+
+	best := res.Scores[ranked[0]]
+	second := 0.0
+	if len(ranked) > 1 {
+		second = res.Scores[ranked[1]]
+	}
+	res.Category = ranked[0]
+	res.Confidence = best / (best + 3) * (1 - 0.5*second/best)
+	return res
+}
+
+func (p Profile) score(text string, matches []models.Match) float64 {
+	score := strongWeight * float64(distinctHits(p.Strong, text))
+
+	weak := weakWeight * float64(distinctHits(p.Weak, text))
+	if weak > maxWeakScore {
+		weak = maxWeakScore
+	}
+	score += weak
+
+	// Each kind of value counts once, however often it occurs
+	for _, s := range p.Signals {
+		for _, m := range matches {
+			if m.Keyword || m.Type != s.Type || (s.Subtype != "" && m.Subtype != s.Subtype) {
+				continue
+			}
+			score += s.Weight
+			break
+		}
+	}
+	return score
+}
+
+// distinctHits counts the different whole words of pattern found in text.
+// Boundaries are checked here as \b does not treat umlauts as letters.
+func distinctHits(pattern *regexp.Regexp, text string) int {
+	if pattern == nil {
+		return 0
+	}
+	seen := make(map[string]bool)
+	for _, loc := range pattern.FindAllStringIndex(text, -1) {
+		if isWholeWord(text, loc[0], loc[1]) {
+			seen[strings.ToLower(text[loc[0]:loc[1]])] = true
+		}
+	}
+	return len(seen)
+}
+
+func isWholeWord(text string, start, end int) bool {
+	if start > 0 {
+		r, _ := utf8.DecodeLastRuneInString(text[:start])
+		if isWordRune(r) {
+			return false
+		}
+	}
+	if end < len(text) {
+		r, _ := utf8.DecodeRuneInString(text[end:])
+		if isWordRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
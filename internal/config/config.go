@@ -1,31 +1,228 @@
 package config
 
 import (
+	"fmt"
 	"runtime"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
-	RootPath    string
-	Workers     int
-	OllamaURL   string
-	OllamaModel string
-	Verbose     bool
+	RootPath string
+	Workers  int
+	Verbose  bool
+
+	// AI backend. AIProvider is one of "ollama", "openai" (any OpenAI-compatible
+	// server such as vLLM, LM Studio or LocalAI) or "llamacpp". AIURL is the
+	// server base URL without an endpoint path.
+	AIProvider string
+	AIURL      string
+	AIModel    string
+	AIAPIKey   string
+	AITimeout  time.Duration
+
+	// Large documents are sent to the AI in chunks of AIChunkTokens tokens that
+	// overlap by AIChunkOverlap tokens. AIMaxChunks and AIMaxTokens cap the
+	// prompts sent per file; candidates beyond the budget are reported unverified.
+	AIChunkTokens  int
+	AIChunkOverlap int
+	AIMaxChunks    int
+	AIMaxTokens    int
+
+	// AIUnverifiable decides what happens to AI findings whose value does not
+	// occur in the extracted text: "flag" keeps them marked as unverified,
+	// "drop" discards them
+	AIUnverifiable string
+
+	// AIMaxRepairs is how often an answer that violates the findings schema
+	// is sent back to the model for correction
+	AIMaxRepairs int
+
+	// Request resilience: at most AIMaxInFlight requests reach the backend at
+	// once; 5xx answers and timeouts are retried AIMaxRetries times starting
+	// at AIRetryDelay. After AIBreakerThreshold consecutive failures the scan
+	// continues regex-only for AIBreakerCooldown before probing again.
+	AIMaxInFlight      int
+	AIMaxRetries       int
+	AIRetryDelay       time.Duration
+	AIBreakerThreshold int
+	AIBreakerCooldown  time.Duration
+
+	// AIPseudonymize replaces detected values with typed placeholders such as
+	// <IBAN_1> before text is sent to the model; verdicts are mapped back
+	// locally. Text that no detector matched is still sent as is.
+	AIPseudonymize bool
+
+	// Few-shot prompting: up to AIFewShotPerType reviewer-labelled examples per
+	// finding type are added to each prompt, within AIFewShotTokens tokens.
+	// 0 disables examples.
+	AIFewShotPerType int
+	AIFewShotTokens  int
+
+	// Document classification: keyword profiles and detector signals assign
+	// every file a category. With AIClassify files with findings whose category
+	// confidence stays below AIClassifyBelow are also shown to the model, as an
+	// excerpt of AIClassifyTokens tokens from the start of the document.
+	AIClassify       bool
+	AIClassifyBelow  float64
+	AIClassifyTokens int
+
+	// AI routing: types in AISkipTypes (e.g. checksum-validated IBAN and
+	// CreditCard) are reported without a model. Types in AILargeTypes go to
+	// AILargeModel, all others to AIModel. Small model findings with a
+	// confidence in [AIEscalateMin, AIEscalateMax) are re-checked by the large
+	// model. Without AILargeModel every reviewed type goes to AIModel.
+	AISkipTypes   []string
+	AILargeModel  string
+	AILargeTypes  []string
+	AIEscalateMin float64
+	AIEscalateMax float64
+
+	// False-positive filter: with AIEmbedFilter, candidates of AIEmbedTypes
+	// and keyword matches are embedded with AIEmbedModel and compared with
+	// reviewed findings before the model sees them. A candidate is dropped
+	// when at least AIEmbedDropShare of its AIEmbedNeighbours nearest reviewed
+	// findings (cosine similarity >= AIEmbedMinSimilarity) were "Incorrect".
+	// llama.cpp embeds with its loaded model and needs --embeddings.
+	AIEmbedFilter        bool
+	AIEmbedModel         string
+	AIEmbedTypes         []string
+	AIEmbedNeighbours    int
+	AIEmbedMinSimilarity float64
+	AIEmbedDropShare     float64
+
+	// AIModelCosts is the price per 1000 tokens by model name, used for the
+	// cost figures in the scan summary
+	AIModelCosts map[string]float64
+
+	// Startup diagnostics: when AIModel is not installed or does not answer
+	// within AIWarmupTimeout, the first working model of AIFallbackModels is
+	// used instead (any installed chat model if the list is empty). Without a
+	// working model the scan runs regex-only.
+	AIFallbackModels []string
+	AIWarmupTimeout  time.Duration
+
+	// AIAuditLog is the JSON lines file recording every AI request without
+	// document text; empty disables it. It is rotated beyond AIAuditMaxSize
+	// bytes or AIAuditMaxAge, keeping AIAuditBackups rotated files.
+	AIAuditLog     string
+	AIAuditMaxSize int64
+	AIAuditMaxAge  time.Duration
+	AIAuditBackups int
+
+	// AICacheTTL is how long AI verdicts are reused for identical prompts on
+	// rescans; 0 disables the cache
+	AICacheTTL time.Duration
+
+	// AdminToken protects the audit log endpoint of the web server; without
+	// it only clients on the same machine may read the log
+	AdminToken string
 
 	// WhitelistPath is the path to the file containing whitelisted terms
 	WhitelistPath string
 	DBPath        string
 
+	// PhoneRegion is the ISO region used to interpret national phone formats (e.g. "DE")
+	PhoneRegion string
+
+	// Keyword scoring: labels such as "Kontonummer" raise the confidence of values
+	// within KeywordWindow bytes. Keywords are only reported on their own when
+	// their type is listed in KeywordFindings (e.g. "Sensitive").
+	KeywordWindow   int
+	KeywordBoost    float64
+	KeywordFindings []string
+
+	// Walker rules. Include and Exclude are gitignore-style patterns relative
+	// to RootPath; with Include set only matching files are scanned. Every
+	// directory may hold an IgnoreFile with further patterns. Files outside
+	// MinSize..MaxSize bytes or modified more than MaxAge ago or less than
+	// MinAge ago are skipped; 0 disables a limit.
+	Include        []string
+	Exclude        []string
+	IgnoreFile     string
+	MinSize        int64
+	MaxSize        int64
+	MaxAge         time.Duration
+	MinAge         time.Duration
+	SkipHidden     bool // Skip files and directories whose name starts with a dot
+	FollowSymlinks bool // Walk symlinked directories, skipping loops; symlinked files are always scanned
+
+	// ShutdownGrace is how long files being scanned may take to finish after
+	// a scan is cancelled before their extraction and AI requests are aborted
+	ShutdownGrace time.Duration
+
 	// Feature Flags
-	FastMode  bool // Skip files > 1MB
-	DisableAI bool // Only use regex
+	FastMode    bool // Skip files > 1MB, on top of MaxSize
+	DisableAI   bool // Only use regex
+	Incremental bool // Carry findings of files unchanged since their last scan forward
 }
 
 func DefaultConfig() *Config {
 	return &Config{
-		Workers:       runtime.NumCPU() * 2, // Aggressive concurrency for I/O bound tasks
-		OllamaURL:     "http://144.76.33.231:11434/api/generate",
-		OllamaModel:   "llama3.2",
-		WhitelistPath: "whitelist.txt",
-		DBPath:        "gdpr-scan-results.db",
+		Workers:        runtime.NumCPU() * 2, // Aggressive concurrency for I/O bound tasks
+		AIProvider:     "ollama",
+		AIURL:          "http://144.76.33.231:11434",
+		AIModel:        "llama3.2",
+		AITimeout:      60 * time.Second,
+		AIChunkTokens:  3000,
+		AIChunkOverlap: 150,
+		AIMaxChunks:    20,
+		AIMaxTokens:    60000,
+		AIUnverifiable: "flag",
+		AIMaxRepairs:   2,
+
+		AIMaxInFlight:        2,
+		AIMaxRetries:         3,
+		AIRetryDelay:         500 * time.Millisecond,
+		AIBreakerThreshold:   5,
+		AIBreakerCooldown:    30 * time.Second,
+		AICacheTTL:           30 * 24 * time.Hour,
+		AIFewShotPerType:     3,
+		AIFewShotTokens:      400,
+		AIClassifyBelow:      0.75,
+		AIClassifyTokens:     800,
+		AILargeTypes:         []string{"Name", "Sensitive"},
+		AIEscalateMin:        0.4,
+		AIEscalateMax:        0.7,
+		AIWarmupTimeout:      2 * time.Minute,
+		AIEmbedModel:         "nomic-embed-text",
+		AIEmbedTypes:         []string{"Name"},
+		AIEmbedNeighbours:    5,
+		AIEmbedMinSimilarity: 0.85,
+		AIEmbedDropShare:     0.8,
+		AIAuditLog:           "ai_audit.jsonl",
+		AIAuditMaxSize:       10 << 20,
+		AIAuditMaxAge:        7 * 24 * time.Hour,
+		AIAuditBackups:       5,
+		WhitelistPath:        "whitelist.txt",
+		DBPath:               "gdpr-scan-results.db",
+		PhoneRegion:          "DE",
+		KeywordWindow:        80,
+		KeywordBoost:         0.6,
+		IgnoreFile:           ".gdprignore",
+		ShutdownGrace:        30 * time.Second,
+	}
+}
+
+// ParseSize parses a size such as "512", "100KB", "20MB" or "1GB". Units
+// are powers of 1024.
+func ParseSize(s string) (int64, error) {
+	units := []struct {
+		suffix string
+		factor int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1}}
+
+	value, factor := strings.ToUpper(strings.TrimSpace(s)), int64(1)
+	for _, u := range units {
+		if strings.HasSuffix(value, u.suffix) {
+			value, factor = strings.TrimSpace(strings.TrimSuffix(value, u.suffix)), u.factor
+			break
+		}
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
 	}
+	return n * factor, nil
 }
@@ -0,0 +1,146 @@
+package eval
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/ai"
+	"github.com/digimosa/ai-gdpr-scan/internal/config"
+)
+
+// writeCorpus creates a labelled corpus of two letters and a negative file
+func writeCorpus(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"letters/invoice.txt": "Rechnung an Max Mustermann\n" +
+			"IBAN: DE89370400440532013000\n" +
+			"E-Mail: max.mustermann@example.com\n",
+		"letters/contact.txt": "Bitte antworten Sie an erika.musterfrau@example.org.\n" +
+			"Kopie an support@example.net\n",
+		"negative.txt": "Lieferung 4711 wurde am Montag versandt.\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// support@example.net is left unlabelled, so every stage reports one
+	// false positive
+	manifest := Manifest{Files: []LabelledFile{
+		{Path: "letters/invoice.txt", Findings: []Expected{
+			{Type: "iban", Value: "DE89370400440532013000"},
+			{Type: "email", Value: "max.mustermann@example.com"},
+		}},
+		{Path: "letters/contact.txt", Findings: []Expected{
+			{Type: "Email", Value: "erika.musterfrau@example.org"},
+		}},
+		{Path: "negative.txt"},
+	}}
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ManifestFile), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func testConfig(t *testing.T) *config.Config {
+	cfg := config.DefaultConfig()
+	cfg.AICacheTTL = 0
+	cfg.AIAuditLog = ""
+	cfg.WhitelistPath = filepath.Join(t.TempDir(), "whitelist.txt")
+	return cfg
+}
+
+func TestRunWithFakeModel(t *testing.T) {
+	corpus := writeCorpus(t)
+
+	fake := &ai.FakeProvider{Respond: ai.ConfirmCandidates}
+	srv, err := ai.StartFakeServer(fake)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	cfg := testConfig(t)
+	cfg.AIProvider, cfg.AIURL, cfg.AIModel = ai.ProviderOllama, srv.URL, "fake"
+	client, err := ai.NewClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := Run(cfg, client, Options{CorpusDir: corpus, Name: "test", MinScore: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Files != 3 || len(res.Errors) > 0 {
+		t.Fatalf("files = %d, errors = %v", res.Files, res.Errors)
+	}
+	if res.Provider != ai.ProviderOllama || res.Model != "fake" {
+		t.Errorf("provider/model = %s/%s", res.Provider, res.Model)
+	}
+	if len(fake.Calls()) == 0 {
+		t.Error("the fake model received no requests")
+	}
+
+	for _, stage := range Stages {
+		total := res.Stages[stage].Total()
+		want := Counts{TruePositives: 3, FalsePositives: 1}
+		if total != want {
+			t.Errorf("%s: counts = %+v, want %+v", stage, total, want)
+		}
+		if p := total.Precision(); p != 0.75 {
+			t.Errorf("%s: precision = %.2f, want 0.75", stage, p)
+		}
+		if r := total.Recall(); r != 1 {
+			t.Errorf("%s: recall = %.2f, want 1", stage, r)
+		}
+	}
+
+	iban := res.Stages[StageAI].Types["IBAN"]
+	if iban.Precision() != 1 || iban.Recall() != 1 {
+		t.Errorf("IBAN: %+v", iban)
+	}
+	email := res.Stages[StageAI].Types["Email"]
+	if email.Precision() != 2.0/3 || email.Recall() != 1 {
+		t.Errorf("Email: %+v", email)
+	}
+}
+
+func TestRunWithoutAI(t *testing.T) {
+	corpus := writeCorpus(t)
+
+	cfg := testConfig(t)
+	cfg.DisableAI = true
+	res, err := Run(cfg, nil, Options{CorpusDir: corpus, MinScore: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Provider != "none" {
+		t.Errorf("provider = %q, want none", res.Provider)
+	}
+	// Without a model the final findings are the detector matches
+	if got, want := res.Stages[StageAI].Total(), res.Stages[StageFiltered].Total(); got != want {
+		t.Errorf("ai stage = %+v, want the filtered stage %+v", got, want)
+	}
+}
+
+func TestLoadManifestRejectsDuplicates(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ManifestFile)
+	os.WriteFile(path, []byte(`{"files": [{"path": "a.txt"}, {"path": "a.txt"}]}`), 0644)
+	if _, err := LoadManifest(dir, ""); err == nil {
+		t.Error("expected an error for a file listed twice")
+	}
+}
@@ -0,0 +1,91 @@
+// Package eval measures detection quality on a labelled corpus. Every file
+// listed in the corpus manifest is run through the scan pipeline and the
+// output of each stage is compared with the expected findings.
+package eval
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// ManifestFile is the default manifest name inside a corpus directory
+const ManifestFile = "manifest.json"
+
+// Manifest lists the corpus files and the personal data each one contains.
+// Files without expected findings are negatives: everything reported for
+// them counts as a false positive.
+//
+//	{"files": [{"path": "letters/cv.txt", "findings": [{"type": "IBAN", "value": "DE89 3704 0044 0532 0130 00"}]}]}
+type Manifest struct {
+	Files  []LabelledFile `json:"files"`
+	Digest string         `json:"-"` // Identifies the manifest content in results
+}
+
+// LabelledFile is one corpus file with its expected findings
+type LabelledFile struct {
+	Path     string     `json:"path"` // Relative to the corpus directory
+	Findings []Expected `json:"findings"`
+}
+
+// Expected is a value the pipeline should report
+type Expected struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// LoadManifest reads and checks a manifest. Paths are resolved against dir.
+func LoadManifest(dir, path string) (*Manifest, error) {
+	if path == "" {
+		path = filepath.Join(dir, ManifestFile)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %v", path, err)
+	}
+	if len(m.Files) == 0 {
+		return nil, fmt.Errorf("manifest %s lists no files", path)
+	}
+
+	sum := sha256.Sum256(data)
+	m.Digest = hex.EncodeToString(sum[:8])
+
+	seen := make(map[string]bool)
+	for i, f := range m.Files {
+		if f.Path == "" {
+			return nil, fmt.Errorf("manifest %s: files[%d] has no path", path, i)
+		}
+		if seen[f.Path] {
+			return nil, fmt.Errorf("manifest %s: %s is listed twice", path, f.Path)
+		}
+		seen[f.Path] = true
+		for j, e := range f.Findings {
+			if e.Type == "" || strings.TrimSpace(e.Value) == "" {
+				return nil, fmt.Errorf("manifest %s: %s findings[%d] needs a type and a value", path, f.Path, j)
+			}
+		}
+		m.Files[i].Path = filepath.Join(dir, filepath.FromSlash(f.Path))
+	}
+	return &m, nil
+}
+
+// normalizeValue makes formatting differences irrelevant when comparing
+// values: case, whitespace and separators such as in "DE89 3704-0044"
+func normalizeValue(value string) string {
+	return strings.ToLower(strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || strings.ContainsRune("-./()", r) {
+			return -1
+		}
+		return r
+	}, value))
+}
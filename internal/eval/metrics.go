@@ -0,0 +1,184 @@
+package eval
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Pipeline stages an evaluation reports on
+const (
+	StageRegex    = "regex"    // Raw detector matches
+	StageScored   = "scored"   // Matches after keyword scoring at or above the threshold
+	StageFiltered = "filtered" // Scored matches kept by the false-positive filter
+	StageAI       = "ai"       // Final findings after AI analysis
+)
+
+// Stages lists the stages in pipeline order
+var Stages = []string{StageRegex, StageScored, StageFiltered, StageAI}
+
+// Counts are the confusion counts of one finding type
+type Counts struct {
+	TruePositives  int `json:"tp"`
+	FalsePositives int `json:"fp"`
+	FalseNegatives int `json:"fn"`
+}
+
+// Precision is the share of reported values that were expected
+func (c Counts) Precision() float64 {
+	return ratio(c.TruePositives, c.TruePositives+c.FalsePositives)
+}
+
+// Recall is the share of expected values that were reported
+func (c Counts) Recall() float64 {
+	return ratio(c.TruePositives, c.TruePositives+c.FalseNegatives)
+}
+
+// F1 is the harmonic mean of precision and recall
+func (c Counts) F1() float64 {
+	p, r := c.Precision(), c.Recall()
+	if p+r == 0 {
+		return 0
+	}
+	return 2 * p * r / (p + r)
+}
+
+func (c *Counts) add(o Counts) {
+	c.TruePositives += o.TruePositives
+	c.FalsePositives += o.FalsePositives
+	c.FalseNegatives += o.FalseNegatives
+}
+
+func ratio(a, b int) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
+
+// StageMetrics holds the counts of one stage per finding type
+type StageMetrics struct {
+	Types map[string]Counts `json:"types"`
+}
+
+// Total sums the counts over all types (micro average)
+func (s StageMetrics) Total() Counts {
+	var total Counts
+	for _, c := range s.Types {
+		total.add(c)
+	}
+	return total
+}
+
+// compare scores the values reported for a file against the expected ones.
+// Values are compared per type after normalization; repeated values count once.
+func compare(expected, reported map[string]map[string]bool, into StageMetrics) {
+	types := make(map[string]bool)
+	for t := range expected {
+		types[t] = true
+	}
+	for t := range reported {
+		types[t] = true
+	}
+
+	for t := range types {
+		c := into.Types[t]
+		for v := range reported[t] {
+			if expected[t][v] {
+				c.TruePositives++
+			} else {
+				c.FalsePositives++
+			}
+		}
+		for v := range expected[t] {
+			if !reported[t][v] {
+				c.FalseNegatives++
+			}
+		}
+		into.Types[t] = c
+	}
+}
+
+// valueSet collects normalized values by type
+type valueSet map[string]map[string]bool
+
+func (s valueSet) add(findingType, value string) {
+	v := normalizeValue(value)
+	if v == "" {
+		return
+	}
+	if s[findingType] == nil {
+		s[findingType] = make(map[string]bool)
+	}
+	s[findingType][v] = true
+}
+
+// WriteTable prints precision, recall and F1 per stage and type
+func (r *Result) WriteTable(w io.Writer) {
+	for _, stage := range Stages {
+		m, ok := r.Stages[stage]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(w, "\nStage %s\n", stage)
+		fmt.Fprintf(w, "  %-14s %5s %5s %5s %9s %9s %9s\n", "Type", "TP", "FP", "FN", "Precision", "Recall", "F1")
+		for _, t := range sortedTypes(m.Types) {
+			writeRow(w, t, m.Types[t])
+		}
+		writeRow(w, "(all)", m.Total())
+	}
+}
+
+func writeRow(w io.Writer, label string, c Counts) {
+	fmt.Fprintf(w, "  %-14s %5d %5d %5d %9.3f %9.3f %9.3f\n", label, c.TruePositives, c.FalsePositives, c.FalseNegatives, c.Precision(), c.Recall(), c.F1())
+}
+
+// WriteComparison prints the F1 change per stage and type between a
+// baseline run and r. Types missing from one run count as zero.
+func (r *Result) WriteComparison(w io.Writer, baseline *Result) {
+	fmt.Fprintf(w, "\nComparison with %s (%s/%s, prompt %s)\n", baseline.Name, baseline.Provider, baseline.Model, baseline.PromptVersion)
+	if baseline.Manifest != r.Manifest {
+		fmt.Fprintln(w, "  WARNING: the runs used different manifests, numbers are not comparable")
+	}
+	fmt.Fprintf(w, "  %-8s %-14s %9s %9s %9s %9s\n", "Stage", "Type", "Prec Δ", "Recall Δ", "F1 before", "F1 after")
+	for _, stage := range Stages {
+		before, ok := baseline.Stages[stage]
+		if !ok {
+			continue // Stage added after the baseline was saved
+		}
+		after := r.Stages[stage]
+		types := make(map[string]Counts)
+		for t := range before.Types {
+			types[t] = Counts{}
+		}
+		for t := range after.Types {
+			types[t] = Counts{}
+		}
+
+		rows := append(sortedTypes(types), "(all)")
+		for _, t := range rows {
+			b, a := before.Types[t], after.Types[t]
+			if t == "(all)" {
+				b, a = before.Total(), after.Total()
+			}
+			marker := ""
+			switch {
+			case a.F1() > b.F1():
+				marker = " +"
+			case a.F1() < b.F1():
+				marker = " -"
+			}
+			fmt.Fprintf(w, "  %-8s %-14s %+9.3f %+9.3f %9.3f %9.3f%s\n", stage, t, a.Precision()-b.Precision(), a.Recall()-b.Recall(), b.F1(), a.F1(), marker)
+		}
+	}
+}
+
+func sortedTypes(types map[string]Counts) []string {
+	names := make([]string, 0, len(types))
+	for t := range types {
+		names = append(names, t)
+	}
+	sort.Slice(names, func(i, j int) bool { return strings.ToLower(names[i]) < strings.ToLower(names[j]) })
+	return names
+}
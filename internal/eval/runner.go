@@ -0,0 +1,179 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/ai"
+	"github.com/digimosa/ai-gdpr-scan/internal/config"
+	"github.com/digimosa/ai-gdpr-scan/internal/models"
+	"github.com/digimosa/ai-gdpr-scan/internal/scanner"
+)
+
+// Options selects the corpus and how stages are cut
+type Options struct {
+	CorpusDir    string
+	ManifestPath string  // Defaults to CorpusDir/manifest.json
+	Name         string  // Label of the run, e.g. "llama3.2 new prompt"
+	MinScore     float64 // Confidence a scored match needs to count in StageScored
+}
+
+// Result is the outcome of one evaluation run. It is saved as JSON so later
+// runs can be compared against it.
+type Result struct {
+	Name          string                  `json:"name"`
+	Time          time.Time               `json:"time"`
+	Manifest      string                  `json:"manifest"` // Digest of the manifest, runs are only comparable on the same one
+	Provider      string                  `json:"provider"`
+	Model         string                  `json:"model"`
+	PromptVersion string                  `json:"prompt_version"`
+	MinScore      float64                 `json:"min_score"`
+	Files         int                     `json:"files"`
+	Errors        []string                `json:"errors,omitempty"` // Files that could not be scanned
+	AI            models.AIStats          `json:"ai"`
+	Stages        map[string]StageMetrics `json:"stages"`
+}
+
+// Run scans every manifest file with the given client and scores each stage.
+// A nil client evaluates the AI stage as regex-only, like a scan without AI.
+func Run(cfg *config.Config, aiClient *ai.Client, opts Options) (*Result, error) {
+	manifest, err := LoadManifest(opts.CorpusDir, opts.ManifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{
+		Name:          opts.Name,
+		Time:          time.Now(),
+		Manifest:      manifest.Digest,
+		Model:         cfg.AIModel,
+		PromptVersion: ai.PromptVersion(),
+		MinScore:      opts.MinScore,
+		Stages:        make(map[string]StageMetrics),
+	}
+	if aiClient != nil && !cfg.DisableAI {
+		res.Provider = aiClient.Provider.Name()
+	} else {
+		res.Provider, res.Model = "none", ""
+	}
+	for _, stage := range Stages {
+		res.Stages[stage] = StageMetrics{Types: make(map[string]Counts)}
+	}
+
+	s := scanner.NewScannerWithAI(cfg, aiClient)
+	for _, file := range manifest.Files {
+		stages := s.ScanFileStages(file.Path)
+		if stages.Result.Error != nil {
+			res.Errors = append(res.Errors, fmt.Sprintf("%s: %v", file.Path, stages.Result.Error))
+		} else if stages.Result.Skipped != "" {
+			res.Errors = append(res.Errors, fmt.Sprintf("%s: skipped: %s", file.Path, stages.Result.Skipped))
+		} else if stages.Result.FileType == "" && stages.Result.MIMEType == "" {
+			res.Errors = append(res.Errors, fmt.Sprintf("%s: unsupported file type", file.Path))
+		}
+		res.Files++
+
+		expected := make(valueSet)
+		for _, e := range file.Findings {
+			expected.add(canonicalType(e.Type), e.Value)
+		}
+
+		regex, scored, filtered, final := stageValues(stages, opts.MinScore)
+		compare(expected, regex, res.Stages[StageRegex])
+		compare(expected, scored, res.Stages[StageScored])
+		compare(expected, filtered, res.Stages[StageFiltered])
+		compare(expected, final, res.Stages[StageAI])
+	}
+
+	if aiClient != nil {
+		res.AI = aiClient.Stats()
+	}
+	return res, nil
+}
+
+// stageValues extracts the reported values of each stage. Final findings
+// from detector matches carry a context snippet instead of the value, so
+// their value is looked up by offset in the scored matches.
+func stageValues(stages scanner.FileStages, minScore float64) (regex, scored, filtered, final valueSet) {
+	regex, scored, filtered, final = make(valueSet), make(valueSet), make(valueSet), make(valueSet)
+
+	for _, m := range stages.Regex {
+		if !m.Keyword {
+			regex.add(string(m.Type), m.Value)
+		}
+	}
+
+	byOffset := make(map[string]string)
+	for _, m := range stages.Scored {
+		byOffset[fmt.Sprintf("%s\x00%d", m.Type, m.Offset)] = m.Value
+		if scoredAbove(m, minScore) {
+			scored.add(string(m.Type), m.Value)
+		}
+	}
+	for _, m := range stages.Filtered {
+		if scoredAbove(m, minScore) {
+			filtered.add(string(m.Type), m.Value)
+		}
+	}
+
+	for _, f := range stages.Result.Findings {
+		value := f.Snippet
+		if f.Origin != models.OriginAI {
+			if v, ok := byOffset[fmt.Sprintf("%s\x00%d", f.Type, f.Offset)]; ok {
+				value = v
+			}
+		}
+		final.add(f.Type, value)
+	}
+	return regex, scored, filtered, final
+}
+
+func scoredAbove(m models.Match, minScore float64) bool {
+	confidence := m.Confidence
+	if confidence == 0 {
+		confidence = 0.5 // Unscored detector, see scoring.baseConfidence
+	}
+	return confidence >= minScore
+}
+
+var knownTypes = []models.FindingType{
+	models.TypeIBAN, models.TypeEmail, models.TypePhone, models.TypeName,
+	models.TypeIdentity, models.TypeFinancial, models.TypeID, models.TypeSensitive,
+	models.TypeCreditCard, models.TypeCredential,
+}
+
+// canonicalType maps manifest type names case-insensitively to the names the
+// pipeline reports
+func canonicalType(name string) string {
+	name = strings.TrimSpace(name)
+	for _, t := range knownTypes {
+		if strings.EqualFold(name, string(t)) {
+			return string(t)
+		}
+	}
+	return name
+}
+
+// Save writes the result as JSON
+func (r *Result) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadResult reads a result written by Save
+func LoadResult(path string) (*Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r Result
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("invalid eval result %s: %v", path, err)
+	}
+	return &r, nil
+}
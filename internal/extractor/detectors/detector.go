@@ -2,6 +2,8 @@ package detectors
 
 import (
 	"regexp"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/digimosa/ai-gdpr-scan/internal/models"
 )
@@ -27,26 +29,7 @@ func (d *BaseRegexDetector) Detect(content string) []models.Match {
 	matches := d.Pattern.FindAllStringIndex(content, -1)
 
 	for _, loc := range matches {
-		start, end := loc[0], loc[1]
-		val := content[start:end]
-
-		// Grab a snippet around the match
-		snippetStart := start - 20
-		if snippetStart < 0 {
-			snippetStart = 0
-		}
-		snippetEnd := end + 20
-		if snippetEnd > len(content) {
-			snippetEnd = len(content)
-		}
-		snippet := content[snippetStart:snippetEnd]
-
-		found = append(found, models.Match{
-			Type:    d.Label,
-			Value:   val,
-			Snippet: snippet,
-			Offset:  int64(start),
-		})
+		found = append(found, newMatch(content, loc[0], loc[1], d.Label))
 	}
 	return found
 }
@@ -54,3 +37,38 @@ func (d *BaseRegexDetector) Detect(content string) []models.Match {
 func (d *BaseRegexDetector) Type() models.FindingType {
 	return d.Label
 }
+
+// newMatch builds a Match for content[start:end] with a snippet of surrounding text
+func newMatch(content string, start, end int, label models.FindingType) models.Match {
+	return models.Match{
+		Type:    label,
+		Value:   content[start:end],
+		Snippet: Snippet(content, start, end),
+		Offset:  int64(start),
+	}
+}
+
+// Snippet returns content[start:end] with up to 20 bytes of surrounding text
+// on either side, the context stored with matches
+func Snippet(content string, start, end int) string {
+	snippetStart := start - 20
+	if snippetStart < 0 {
+		snippetStart = 0
+	}
+	snippetEnd := end + 20
+	if snippetEnd > len(content) {
+		snippetEnd = len(content)
+	}
+	return content[snippetStart:snippetEnd]
+}
+
+// isWholeWord reports whether content[start:end] is not glued to letters or digits
+func isWholeWord(content string, start, end int) bool {
+	if r, _ := utf8.DecodeLastRuneInString(content[:start]); start > 0 && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+		return false
+	}
+	if r, _ := utf8.DecodeRuneInString(content[end:]); end < len(content) && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+		return false
+	}
+	return true
+}
@@ -11,9 +11,12 @@ var (
 	identityKeywords  = regexp.MustCompile(`(?i)(Name|Firstname|Lastname|Fullname|Surname|Vorname|Nachname|Familienname|Address|Street|ZIP|City|Residence|P\.O\.\s*Box|Straße|PLZ|Wohnort|Anschrift|Postfach|Email|Phone|Mobile|Fax|E-Mail|Telefon|Handy|Rufnummer|Birthdate|Place\s+of\s+birth|Gender|Age|Geburtsdatum|Geburtsort|Geschlecht|Alter)`)
 	financialKeywords = regexp.MustCompile(`(?i)(Account|Sort\s+Code|Kontonummer|BLZ|Bankverbindung|Credit\s+card|Visa|Mastercard|CVV|Kreditkarte|Karteninhaber|Ablaufdatum|Tax\s+ID|Tax\s+Number|VAT\s+ID|Steuer-ID|Steuernummer|USt-IdNr)`)
 	idKeywords        = regexp.MustCompile(`(?i)(Passport|Driver's\s+License|SSN|Reisepassnummer|Führerschein|Ausweis|National\s+Insurance|Health\s+Insurance|Sozialversicherung|Krankenkasse|Vers-Nr)`)
-	sensitiveKeywords = regexp.MustCompile(`(?i)(Medical|Diagnosis|Patient|Therapy|Arzt|Befund|Diagnose|Krankmeldung|Religion|Political|Church|Union|Konfession|Partei|Gewerkschaft|Criminal|Offense|Court|Lawyer|Vorstrafe|Urteil|Aktenzeichen|Anwalt)`)
+	sensitiveKeywords = regexp.MustCompile(`(?i)(Medical|Diagnosis|Patient|Therapy|Arzt|Befund|Diagnose|Krankmeldung|Religion|Political|Konfession|Partei|Gewerkschaft|Criminal|Offense|Lawyer|Vorstrafe|Urteil|Aktenzeichen|Anwalt)`)
 )
 
+// KeywordDetector finds labels such as "Kontonummer" or "Birthdate".
+// Its matches are context signals (Match.Keyword) for the scoring stage,
+// not findings on their own.
 type KeywordDetector struct {
 	BaseRegexDetector
 }
@@ -33,3 +36,19 @@ func NewOfficialIDKeywordDetector() *KeywordDetector {
 func NewSensitiveKeywordDetector() *KeywordDetector {
 	return &KeywordDetector{BaseRegexDetector{Pattern: sensitiveKeywords, Label: models.TypeSensitive}}
 }
+
+// Detect keeps only whole-word keywords, so "Page" no longer yields "Age"
+// and "Barzt" no longer yields "Arzt".
+func (d *KeywordDetector) Detect(content string) []models.Match {
+	var found []models.Match
+	for _, loc := range d.Pattern.FindAllStringIndex(content, -1) {
+		if !isWholeWord(content, loc[0], loc[1]) {
+			continue
+		}
+
+		m := newMatch(content, loc[0], loc[1], d.Label)
+		m.Keyword = true
+		found = append(found, m)
+	}
+	return found
+}
@@ -2,14 +2,26 @@ package detectors
 
 import (
 	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/digimosa/ai-gdpr-scan/internal/models"
 )
 
-// NameRegex: Capitalized words (First Last, optionally Middle). Relaxed to len 1+ for parts like "O'Neil" or "Al" if we support apostrophes later, but let's stick to simple German/English names.
-// Previous: [a-z]{2,} missed short names or names with accents if not covered. Let's make it broader.
-// New: Capitalized word, space, Capitalized word. Optional middle name.
-var namePattern = regexp.MustCompile(`\b[A-ZÄÖÜ][a-zäöüß]+(?:[- ]?[A-ZÄÖÜ][a-zäöüß]+){1,3}\b`)
+// namePattern finds runs of capitalized words, optionally preceded by honorifics.
+// In German every noun is capitalized, so this only produces candidates;
+// scoreName decides which of them look like a person.
+var namePattern = regexp.MustCompile(`(?:(?:Herrn?|Frau|Dr\.|Prof\.|Mr\.?|Mrs\.?|Ms\.?)[ \t]+)*\p{Lu}\p{Ll}+(?:(?:-|[ \t]+)\p{Lu}\p{Ll}+){0,4}`)
+
+// honorifics boost the following name and may introduce a bare surname ("Frau Schmidt")
+var honorifics = map[string]bool{
+	"herr": true, "herrn": true, "frau": true, "dr": true, "prof": true,
+	"mr": true, "mrs": true, "ms": true,
+}
+
+// NameMinScore is the score a candidate needs to be reported
+const NameMinScore = 0.5
 
 type NameDetector struct {
 	BaseRegexDetector
@@ -23,3 +35,139 @@ func NewNameDetector() *NameDetector {
 		},
 	}
 }
+
+// nameToken is a word of a candidate with its position in the content
+type nameToken struct {
+	text       string
+	start, end int
+}
+
+// Detect splits candidates at stop-words and honorifics and keeps the runs
+// that score as a person name against the bundled gazetteers.
+func (d *NameDetector) Detect(content string) []models.Match {
+	gaz := getNameGazetteer()
+	var found []models.Match
+
+	for _, loc := range d.Pattern.FindAllStringIndex(content, -1) {
+		start, end := loc[0], loc[1]
+		// RE2 word boundaries are ASCII only, so check umlaut neighbours by hand
+		if r, _ := utf8.DecodeLastRuneInString(content[:start]); start > 0 && unicode.IsLetter(r) {
+			continue
+		}
+
+		var run []nameToken
+		honorific := false
+		flush := func() {
+			if len(run) > 0 {
+				if score := scoreName(gaz, run, honorific); score >= NameMinScore {
+					m := newMatch(content, run[0].start, run[len(run)-1].end, d.Label)
+					m.Confidence = score
+					found = append(found, m)
+				}
+			}
+			run = nil
+			honorific = false
+		}
+
+		for _, tok := range splitNameTokens(content, start, end) {
+			key := normalizeNameToken(strings.TrimSuffix(tok.text, "."))
+			switch {
+			case honorifics[key]:
+				if len(run) > 0 {
+					flush()
+				}
+				honorific = true
+			case isNameStopword(gaz, tok.text):
+				flush()
+			default:
+				run = append(run, tok)
+			}
+		}
+		flush()
+	}
+	return found
+}
+
+// scoreName rates a run of capitalized tokens between 0 and 0.95
+func scoreName(gaz *nameGazetteer, run []nameToken, honorific bool) float64 {
+	first := normalizeNameToken(run[0].text)
+	last := normalizeNameToken(run[len(run)-1].text)
+
+	score := 0.0
+	if honorific {
+		score += 0.35
+	}
+
+	if len(run) == 1 {
+		// A single capitalized word is only a name when introduced by an honorific
+		if !honorific {
+			return 0
+		}
+		if e, ok := lookupSurname(gaz, first); ok {
+			score += 0.2 + 0.1*float64(e.Frequency)/100
+		} else {
+			score += 0.15
+		}
+		return capScore(score)
+	}
+
+	if e, ok := gaz.given[first]; ok {
+		score += 0.45 + 0.1*float64(e.Frequency)/100
+	}
+	if e, ok := lookupSurname(gaz, last); ok {
+		score += 0.3 + 0.1*float64(e.Frequency)/100
+	}
+	return capScore(score)
+}
+
+// lookupSurname also resolves double-barrelled names ("Müller-Lüdenscheidt")
+func lookupSurname(gaz *nameGazetteer, token string) (gazetteerEntry, bool) {
+	if e, ok := gaz.surnames[token]; ok {
+		return e, true
+	}
+	for _, part := range strings.Split(token, "-") {
+		if e, ok := gaz.surnames[part]; ok {
+			return e, true
+		}
+	}
+	return gazetteerEntry{}, false
+}
+
+func isNameStopword(gaz *nameGazetteer, token string) bool {
+	for _, part := range strings.Split(token, "-") {
+		if gaz.stopwords[normalizeNameToken(part)] {
+			return true
+		}
+	}
+	return false
+}
+
+func capScore(s float64) float64 {
+	if s > 0.95 {
+		return 0.95
+	}
+	return s
+}
+
+// splitNameTokens returns the whitespace separated words of content[start:end]
+func splitNameTokens(content string, start, end int) []nameToken {
+	var tokens []nameToken
+	tokStart := -1
+	for i := start; i < end; i++ {
+		c := content[i]
+		if c == ' ' || c == '\t' {
+			if tokStart >= 0 {
+				tokens = append(tokens, nameToken{text: content[tokStart:i], start: tokStart, end: i})
+				tokStart = -1
+			}
+			continue
+		}
+		if tokStart < 0 {
+			tokStart = i
+		}
+	}
+	if tokStart >= 0 {
+		tokens = append(tokens, nameToken{text: content[tokStart:end], start: tokStart, end: end})
+	}
+	return tokens
+}
@@ -0,0 +1,91 @@
+package detectors
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Gazetteers are tab separated: name, comma separated country codes, frequency (1-100)
+var (
+	//go:embed data/given_names.tsv
+	givenNamesTSV []byte
+
+	//go:embed data/surnames.tsv
+	surnamesTSV []byte
+
+	//go:embed data/name_stopwords.txt
+	nameStopwordsTXT []byte
+)
+
+// gazetteerEntry holds the metadata for a known name
+type gazetteerEntry struct {
+	Countries []string
+	Frequency int // 1-100, relative within the source country
+}
+
+type nameGazetteer struct {
+	given     map[string]gazetteerEntry
+	surnames  map[string]gazetteerEntry
+	stopwords map[string]bool
+}
+
+var (
+	nameGazetteerOnce sync.Once
+	loadedGazetteer   *nameGazetteer
+)
+
+func getNameGazetteer() *nameGazetteer {
+	nameGazetteerOnce.Do(func() {
+		loadedGazetteer = &nameGazetteer{
+			given:     parseGazetteer(givenNamesTSV),
+			surnames:  parseGazetteer(surnamesTSV),
+			stopwords: parseWordList(nameStopwordsTXT),
+		}
+	})
+	return loadedGazetteer
+}
+
+func parseGazetteer(data []byte) map[string]gazetteerEntry {
+	out := make(map[string]gazetteerEntry)
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) < 3 {
+			continue
+		}
+		freq, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+		out[normalizeNameToken(fields[0])] = gazetteerEntry{
+			Countries: strings.Split(fields[1], ","),
+			Frequency: freq,
+		}
+	}
+	return out
+}
+
+func parseWordList(data []byte) map[string]bool {
+	out := make(map[string]bool)
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		out[normalizeNameToken(line)] = true
+	}
+	return out
+}
+
+func normalizeNameToken(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
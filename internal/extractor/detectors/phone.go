@@ -2,22 +2,111 @@ package detectors
 
 import (
 	"regexp"
+	"strings"
 
 	"github.com/digimosa/ai-gdpr-scan/internal/models"
 )
 
-// PhoneRegex: International (+49) or local (0176) formats
-var phonePattern = regexp.MustCompile(`(\+|00)[0-9][0-9 \-\./]{6,}`)
+// phonePattern finds candidate digit groups: an optional "+CC" part, an optional
+// bracketed area code and digit blocks joined by single separators.
+// It is deliberately loose; ParsePhoneNumber decides what is a real number.
+var phonePattern = regexp.MustCompile(`(?:\+\s?\d{1,3}(?:\s?\(0\))?(?:\s?[\-./]\s?|\s)?)?(?:\(\d{2,6}\)\s?)?\d{2,}(?:(?:\s?[\-./]\s?|\s)\d{2,}){0,6}`)
 
 type PhoneDetector struct {
 	BaseRegexDetector
+	Region string // Region used to interpret national formats, e.g. "DE"
 }
 
-func NewPhoneDetector() *PhoneDetector {
+// NewPhoneDetector creates a detector for numbers written in the national
+// format of region (see SupportedPhoneRegions and config.PhoneRegion) or in
+// international format. Without a region only the latter are found.
+func NewPhoneDetector(region string) *PhoneDetector {
 	return &PhoneDetector{
 		BaseRegexDetector: BaseRegexDetector{
 			Pattern: phonePattern,
 			Label:   models.TypePhone,
 		},
+		Region: strings.ToUpper(region),
 	}
 }
+
+// Detect validates candidates against the numbering plan and classifies them
+func (d *PhoneDetector) Detect(content string) []models.Match {
+	var verified []models.Match
+
+	for _, loc := range d.Pattern.FindAllStringIndex(content, -1) {
+		start, end := loc[0], loc[1]
+
+		// Reject digits glued to words or other numbers (IDs, hashes, amounts)
+		if !isWholeWord(content, start, end) {
+			continue
+		}
+
+		verified = append(verified, d.validateCandidate(content, start, end)...)
+	}
+	return verified
+}
+
+// validateCandidate tries the whole candidate first and then shorter runs of
+// its digit groups, so a date or amount in front of a number does not hide it.
+func (d *PhoneDetector) validateCandidate(content string, start, end int) []models.Match {
+	starts, ends := groupBoundaries(content, start, end)
+
+	var found []models.Match
+	for i := 0; i < len(starts); i++ {
+		for j := len(ends) - 1; j >= 0; j-- {
+			if ends[j] <= starts[i] {
+				break
+			}
+			value := content[starts[i]:ends[j]]
+			if mixesDotSeparators(value) {
+				continue
+			}
+			num, ok := ParsePhoneNumber(value, d.Region)
+			if !ok {
+				continue
+			}
+
+			m := newMatch(content, starts[i], ends[j], d.Label)
+			m.Subtype = num.Type
+			found = append(found, m)
+
+			// Continue after the accepted number
+			for i+1 < len(starts) && starts[i+1] < ends[j] {
+				i++
+			}
+			break
+		}
+	}
+	return found
+}
+
+// groupBoundaries returns the start and end positions of the digit groups in content[start:end]
+func groupBoundaries(content string, start, end int) (starts, ends []int) {
+	inGroup := false
+	for i := start; i < end; i++ {
+		c := content[i]
+		isPart := (c >= '0' && c <= '9') || c == '+' || c == '(' || c == ')'
+		if isPart && !inGroup {
+			starts = append(starts, i)
+			inGroup = true
+		} else if !isPart && inGroup {
+			ends = append(ends, i)
+			inGroup = false
+		}
+	}
+	if inGroup {
+		ends = append(ends, end)
+	}
+	return starts, ends
+}
+
+// mixesDotSeparators reports whether dots are combined with other separators.
+// Dotted numbers ("030.123.4567") use dots throughout, so "12.03.2024 0176" is
+// a date followed by something else rather than one number.
+func mixesDotSeparators(value string) bool {
+	if !strings.Contains(value, ".") {
+		return false
+	}
+	return strings.ContainsAny(value, " -/")
+}
@@ -0,0 +1,252 @@
+package detectors
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/models"
+)
+
+// Credential classifications, used as Match.Subtype of TypeCredential
+const (
+	CredentialPrivateKey       = "private_key"
+	CredentialAPIToken         = "api_token"
+	CredentialConnectionString = "connection_string"
+	CredentialPassword         = "password"
+	CredentialPasswordHash     = "password_hash"
+	CredentialHighEntropy      = "high_entropy"
+)
+
+// secretRule is a pattern for one kind of credential. If the pattern has a
+// group, group 1 holds the secret itself; otherwise the whole match is the secret.
+type secretRule struct {
+	Subtype    string
+	Pattern    *regexp.Regexp
+	Confidence float64
+}
+
+var secretRules = []secretRule{
+	{CredentialPrivateKey, regexp.MustCompile(`-----BEGIN (?:RSA |EC |DSA |OPENSSH |ENCRYPTED |PGP )?PRIVATE KEY(?: BLOCK)?-----(?:\r?\n([A-Za-z0-9+/=]{16,}))?`), 0.95},
+
+	// Known token prefixes
+	{CredentialAPIToken, regexp.MustCompile(`AKIA[0-9A-Z]{16}`), 0.9},                                                    // AWS access key
+	{CredentialAPIToken, regexp.MustCompile(`gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,}`), 0.95},            // GitHub
+	{CredentialAPIToken, regexp.MustCompile(`glpat-[A-Za-z0-9_\-]{20,}`), 0.95},                                          // GitLab
+	{CredentialAPIToken, regexp.MustCompile(`xox[abprs]-[A-Za-z0-9\-]{10,}`), 0.9},                                       // Slack
+	{CredentialAPIToken, regexp.MustCompile(`(?:sk|rk)_live_[A-Za-z0-9]{20,}`), 0.95},                                    // Stripe
+	{CredentialAPIToken, regexp.MustCompile(`AIza[0-9A-Za-z_\-]{35}`), 0.9},                                              // Google API key
+	{CredentialAPIToken, regexp.MustCompile(`sk-(?:proj-)?[A-Za-z0-9_\-]{32,}`), 0.85},                                   // OpenAI style
+	{CredentialAPIToken, regexp.MustCompile(`SG\.[A-Za-z0-9_\-]{22}\.[A-Za-z0-9_\-]{43}`), 0.95},                         // SendGrid
+	{CredentialAPIToken, regexp.MustCompile(`eyJ[A-Za-z0-9_\-]{10,}\.eyJ[A-Za-z0-9_\-]{10,}\.[A-Za-z0-9_\-]{10,}`), 0.8}, // JWT
+
+	// Connection strings with inline credentials
+	{CredentialConnectionString, regexp.MustCompile(`(?i)(?:postgres(?:ql)?|mysql|mariadb|mongodb(?:\+srv)?|redis|amqps?|mssql|sqlserver|s?ftp|ldaps?)://[^\s:/@]+:([^\s@/]+)@[^\s/"']+`), 0.9},
+	{CredentialConnectionString, regexp.MustCompile(`(?i)(?:Server|Data Source|Host)=[^;\n]+;[^\n]*?(?:Password|Pwd)=([^;\s"']+)`), 0.85},
+
+	// .htpasswd and shadow style lines
+	{CredentialPasswordHash, regexp.MustCompile(`(?m)^[A-Za-z0-9._\-]+:(\$apr1\$[./0-9A-Za-z]{1,8}\$[./0-9A-Za-z]{22}|\$2[aby]\$\d{2}\$[./0-9A-Za-z]{53}|\{SHA\}[A-Za-z0-9+/=]{28}|\$[56]\$[^:\s]{8,})`), 0.9},
+
+	// Assignments such as password=..., "api_key": "..."
+	{CredentialPassword, regexp.MustCompile(`(?i)(?:password|passwd|passwort|kennwort|pwd|secret|api[_\-]?key|access[_\-]?token|auth[_\-]?token|client[_\-]?secret)["']?\s*[:=]\s*["']?([^\s"',;<>]{6,})`), 0.75},
+}
+
+var (
+	// Candidate for a random token: long run of base64/url-safe characters
+	entropyTokenPattern = regexp.MustCompile(`[A-Za-z0-9+/_\-]{24,}={0,2}`)
+	// Words that make a random-looking token a likely secret
+	secretContext = regexp.MustCompile(`(?i)(key|token|secret|password|passwort|credential|bearer|auth)`)
+	// Values that are clearly not real passwords
+	secretPlaceholders = regexp.MustCompile(`(?i)^(\*+|x+|\.+|null|none|true|false|changeme|password|passwort|example|your[_\-]?\w*|\$\{.*|\{\{.*|%s|<.*)$`)
+	// CSV header columns holding passwords
+	passwordColumn = regexp.MustCompile(`(?i)^\s*"?(password|passwort|kennwort|pwd|passwd|pass|password_hash|passwordhash)"?\s*$`)
+)
+
+// SecretDetector finds credentials that are often exported next to personal
+// data: private keys, API tokens, connection strings, password assignments,
+// .htpasswd lines, password columns in CSVs and high-entropy tokens.
+// Snippets are masked so secrets never end up in reports or prompts.
+type SecretDetector struct {
+	BaseRegexDetector
+}
+
+func NewSecretDetector() *SecretDetector {
+	return &SecretDetector{
+		BaseRegexDetector: BaseRegexDetector{Label: models.TypeCredential},
+	}
+}
+
+func (d *SecretDetector) Detect(content string) []models.Match {
+	var found []models.Match
+
+	for _, rule := range secretRules {
+		for _, loc := range rule.Pattern.FindAllStringSubmatchIndex(content, -1) {
+			start, end := loc[0], loc[1]
+			secretStart, secretEnd := start, end
+			if rule.Pattern.NumSubexp() > 0 {
+				// An unmatched optional group leaves nothing to mask (e.g. a bare PEM header)
+				secretStart, secretEnd = end, end
+				if loc[2] >= 0 {
+					secretStart, secretEnd = loc[2], loc[3]
+				}
+			}
+			if rule.Subtype == CredentialPassword && secretPlaceholders.MatchString(content[secretStart:secretEnd]) {
+				continue
+			}
+			found = append(found, d.match(content, start, end, secretStart, secretEnd, rule.Subtype, rule.Confidence))
+		}
+	}
+
+	found = append(found, d.detectPasswordColumns(content)...)
+	found = append(found, d.detectHighEntropy(content)...)
+
+	return dropOverlapping(found)
+}
+
+// detectPasswordColumns reports the values below a password column of a CSV header
+func (d *SecretDetector) detectPasswordColumns(content string) []models.Match {
+	var found []models.Match
+
+	column := -1
+	sep := ""
+	pos := 0
+	for _, line := range strings.SplitAfter(content, "\n") {
+		lineStart := pos
+		pos += len(line)
+		text := strings.TrimRight(line, "\r\n")
+
+		if column < 0 {
+			for _, s := range []string{";", ",", "\t"} {
+				if !strings.Contains(text, s) {
+					continue
+				}
+				for i, field := range strings.Split(text, s) {
+					if passwordColumn.MatchString(field) {
+						column, sep = i, s
+						break
+					}
+				}
+				if column >= 0 {
+					break
+				}
+			}
+			continue
+		}
+
+		fields := strings.Split(text, sep)
+		if column >= len(fields) {
+			continue
+		}
+		offset := lineStart
+		for _, f := range fields[:column] {
+			offset += len(f) + len(sep)
+		}
+		value := strings.Trim(fields[column], `" `)
+		if len(value) < 4 || secretPlaceholders.MatchString(value) {
+			continue
+		}
+		valueStart := offset + strings.Index(fields[column], value)
+		m := d.match(content, valueStart, valueStart+len(value), valueStart, valueStart+len(value), CredentialPassword, 0.8)
+		// Neighbouring rows hold more passwords, so the snippet must not show them
+		m.Snippet = "password column: " + MaskSecret(value)
+		found = append(found, m)
+	}
+	return found
+}
+
+// detectHighEntropy reports random-looking tokens. Without a nearby label
+// such as "token" or "key" the token has to be very random to count.
+func (d *SecretDetector) detectHighEntropy(content string) []models.Match {
+	var found []models.Match
+	for _, loc := range entropyTokenPattern.FindAllStringIndex(content, -1) {
+		start, end := loc[0], loc[1]
+		token := content[start:end]
+		// Longer runs are usually embedded files (base64 images) rather than secrets
+		if len(token) > 128 || !isWholeWord(content, start, end) || !hasMixedClasses(token) {
+			continue
+		}
+
+		entropy := shannonEntropy(token)
+		labelled := hasContextBefore(content, start, secretContext)
+		switch {
+		case labelled && entropy >= 4.0:
+			found = append(found, d.match(content, start, end, start, end, CredentialHighEntropy, 0.7))
+		case entropy >= 4.8:
+			found = append(found, d.match(content, start, end, start, end, CredentialHighEntropy, 0.5))
+		}
+	}
+	return found
+}
+
+// match builds a credential match whose snippet has the secret masked
+func (d *SecretDetector) match(content string, start, end, secretStart, secretEnd int, subtype string, confidence float64) models.Match {
+	m := newMatch(content, start, end, d.Label)
+	m.Subtype = subtype
+	m.Confidence = confidence
+	if secretEnd > secretStart {
+		secret := content[secretStart:secretEnd]
+		m.Snippet = strings.ReplaceAll(m.Snippet, secret, MaskSecret(secret))
+	}
+	return m
+}
+
+// MaskSecret keeps the first four characters of a secret, which is enough to
+// recognise a key prefix, and hides the rest
+func MaskSecret(secret string) string {
+	if len(secret) <= 4 {
+		return strings.Repeat("*", len(secret))
+	}
+	return secret[:4] + strings.Repeat("*", 8)
+}
+
+// dropOverlapping keeps the most confident match where several rules hit the same text
+func dropOverlapping(matches []models.Match) []models.Match {
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Confidence > matches[j].Confidence })
+
+	var kept []models.Match
+	for _, m := range matches {
+		end := m.Offset + int64(len(m.Value))
+		overlaps := false
+		for _, k := range kept {
+			if m.Offset < k.Offset+int64(len(k.Value)) && k.Offset < end {
+				overlaps = true
+				break
+			}
+		}
+		if !overlaps {
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
+
+func hasMixedClasses(s string) bool {
+	var upper, lower, digit bool
+	for _, r := range s {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= '0' && r <= '9':
+			digit = true
+		}
+	}
+	return upper && lower && digit
+}
+
+// shannonEntropy returns the entropy in bits per character
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+	n := float64(len(s))
+	entropy := 0.0
+	for _, c := range counts {
+		p := float64(c) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
@@ -0,0 +1,171 @@
+package detectors
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/models"
+)
+
+// GDPR Article 9 special categories, used as Match.Subtype of TypeSensitive
+const (
+	Article9Health            = "health"
+	Article9Religion          = "religion"
+	Article9Politics          = "politics"
+	Article9Union             = "union"
+	Article9SexualOrientation = "sexual_orientation"
+	Article9Biometric         = "biometric"
+	Article9Genetic           = "genetic"
+)
+
+var (
+	// ICD-10 category with optional subcategory, e.g. "F32" or "F32.1"
+	icdPattern = regexp.MustCompile(`[A-Z]\d{2}(?:\.\d{1,2})?`)
+	// ICD-10-GM certainty ("G"esichert, "V"erdacht, "Z"ustand nach, "A"usschluss) and side (L/R/B)
+	icdSuffixPattern = regexp.MustCompile(`^[ \t]?[GVZA](?:[ \t]?[LRB])?`)
+	// OPS procedure code, e.g. "5-470" or "5-470.11"
+	opsPattern = regexp.MustCompile(`\d-\d{3}(?:\.[0-9a-z]{1,2})?`)
+	// Labels that put a bare code into medical context
+	medicalCodeContext = regexp.MustCompile(`(?i)(ICD|OPS|Diagnosen?|Diagnosis|Dx|Befund|Prozedur|Procedure)`)
+)
+
+// specialRule is a phrasing pattern for one Article 9 category
+type specialRule struct {
+	Category   string
+	Pattern    *regexp.Regexp
+	Confidence float64
+}
+
+var specialRules = []specialRule{
+	{Article9Health, regexp.MustCompile(`(?i)(Arbeitsunfähigkeitsbescheinigung|AU-Bescheinigung|Krankschreibung|Schwerbehinderung|schwerbehindert|Grad der Behinderung|GdB\s*:?\s*\d{2,3}|Schwangerschaft|schwanger|Psychotherapie|Chemotherapie|Dialyse|Entzugstherapie|HIV[- ]positiv|HIV[- ]positive|sick note|disability|pregnancy|pregnant|psychiatric|chemotherapy|dialysis)`), 0.65},
+
+	{Article9Religion, regexp.MustCompile(`(?i)(?:Konfession|Religionszugehörigkeit|Religion|Glaubensbekenntnis|Religionsgemeinschaft|Kirchensteuermerkmal|KiSt|religious affiliation|denomination)\s*[:=]?\s*(?:röm\.?\s*-?\s*kath(?:olisch|\.)?|katholisch|evangelisch-lutherisch|evangelisch|ev\.|rk|ev|lt|neuapostolisch|altkatholisch|orthodox|muslimisch|islamisch|jüdisch|israelitisch|buddhistisch|hinduistisch|konfessionslos|roman catholic|catholic|protestant|anglican|lutheran|baptist|methodist|muslim|jewish|christian|buddhist|hindu|sikh|atheist)`), 0.85},
+	{Article9Religion, regexp.MustCompile(`(?i)(Kirchenaustritt|Taufschein|Kirchenmitgliedschaft|Moscheegemeinde|Synagogengemeinde|church membership|baptism certificate)`), 0.65},
+
+	{Article9Politics, regexp.MustCompile(`(?i)(Parteimitgliedschaft|Parteimitglied|Parteibuch|Parteibeitrag|Mitglied (?:der|in der|bei der) (?:CDU|CSU|SPD|FDP|AfD|Grünen|Linken)|party membership|party member|member of the (?:Labour|Conservative|Liberal Democrat|Democratic|Republican|Green) Party|political affiliation|politische (?:Einstellung|Gesinnung|Überzeugung))`), 0.75},
+
+	{Article9Union, regexp.MustCompile(`(?i)(Gewerkschaftsmitgliedschaft|Gewerkschaftsmitglied|Gewerkschaftszugehörigkeit|Gewerkschaftsbeitrag|Mitglied (?:der|bei der|in der) (?:Gewerkschaft|ver\.di|IG Metall|IG BCE|GEW|EVG|NGG|IG BAU|GdP)|(?:ver\.di|IG Metall|IG BCE)-Mitglied|trade union membership|trade union member|union membership|union member|union dues|member of (?:the )?(?:Unite|UNISON|GMB|Teamsters|SEIU))`), 0.8},
+
+	{Article9SexualOrientation, regexp.MustCompile(`(?i)(sexuelle Orientierung|sexual orientation|homosexuell|heterosexuell|bisexuell|homosexual|heterosexual|bisexual|schwul|lesbisch|lesbian|eingetragene Lebenspartnerschaft|same-sex partner)`), 0.7},
+
+	{Article9Biometric, regexp.MustCompile(`(?i)(Fingerabdrücke|Fingerabdruck|fingerprints|fingerprint|Gesichtserkennung|facial recognition|face template|biometrische \w+|biometric \w+|Iris-Scan|iris scan|retina scan|Venenscan|voiceprint|Stimmprofil)`), 0.7},
+
+	{Article9Genetic, regexp.MustCompile(`(?i)(DNA-Analyse|DNA-Test|DNA test|Gentest|Genanalyse|genetic testing|genetic test|genetische (?:Untersuchung|Veranlagung|Disposition)|genotype|Genotyp|BRCA[12](?:-Mutation)?|Erbkrankheit|hereditary disease|genome sequencing)`), 0.75},
+	{Article9Genetic, regexp.MustCompile(`rs\d{4,}`), 0.6}, // dbSNP identifiers
+}
+
+// SpecialCategoryDetector finds GDPR Article 9 data: medical codes and
+// medication names from the bundled vocabularies, and phrasing for religion,
+// politics, union membership, sexual orientation, biometric and genetic data.
+type SpecialCategoryDetector struct {
+	BaseRegexDetector
+}
+
+func NewSpecialCategoryDetector() *SpecialCategoryDetector {
+	return &SpecialCategoryDetector{
+		BaseRegexDetector: BaseRegexDetector{Label: models.TypeSensitive},
+	}
+}
+
+func (d *SpecialCategoryDetector) Detect(content string) []models.Match {
+	vocab := getMedicalVocabulary()
+
+	var found []models.Match
+	found = append(found, d.detectICD(content, vocab)...)
+	found = append(found, d.detectOPS(content, vocab)...)
+
+	for _, loc := range vocab.medications.FindAllStringIndex(content, -1) {
+		if isWholeWord(content, loc[0], loc[1]) {
+			found = append(found, d.match(content, loc[0], loc[1], Article9Health, 0.6))
+		}
+	}
+
+	for _, rule := range specialRules {
+		for _, loc := range rule.Pattern.FindAllStringIndex(content, -1) {
+			if isWholeWord(content, loc[0], loc[1]) {
+				found = append(found, d.match(content, loc[0], loc[1], rule.Category, rule.Confidence))
+			}
+		}
+	}
+	return found
+}
+
+// detectICD accepts codes from a known chapter. Bare three character codes
+// ("F32") also look like cell references or part numbers, so they need a
+// GM certainty marker or a medical label in front.
+func (d *SpecialCategoryDetector) detectICD(content string, vocab *medicalVocabulary) []models.Match {
+	var found []models.Match
+	for _, loc := range icdPattern.FindAllStringIndex(content, -1) {
+		start, end := loc[0], loc[1]
+		if !isWholeWord(content, start, end) {
+			continue
+		}
+		code := content[start:end]
+		if _, ok := vocab.icdChapterFor(code[:3]); !ok {
+			continue
+		}
+
+		confidence := 0.0
+		if strings.Contains(code, ".") {
+			confidence = 0.55
+		}
+		if suffix := icdSuffixPattern.FindString(content[end:]); suffix != "" && isWholeWord(content, start, end+len(suffix)) {
+			end += len(suffix)
+			confidence += 0.25
+		}
+		labelled := hasContextBefore(content, start, medicalCodeContext)
+		if labelled {
+			confidence += 0.3
+		}
+		// External causes (V01-Y84) collide with version numbers and are never used on their own
+		if confidence == 0 || (strings.ContainsAny(code[:1], "VWXY") && !labelled) {
+			continue
+		}
+		found = append(found, d.match(content, start, end, Article9Health, capScore(confidence)))
+	}
+	return found
+}
+
+// detectOPS accepts procedure codes from a known chapter with a subcode or an OPS label
+func (d *SpecialCategoryDetector) detectOPS(content string, vocab *medicalVocabulary) []models.Match {
+	var found []models.Match
+	for _, loc := range opsPattern.FindAllStringIndex(content, -1) {
+		start, end := loc[0], loc[1]
+		if !isWholeWord(content, start, end) {
+			continue
+		}
+		code := content[start:end]
+		if _, ok := vocab.opsChapters[code[:1]]; !ok {
+			continue
+		}
+
+		confidence := 0.0
+		if strings.Contains(code, ".") {
+			confidence = 0.5
+		}
+		if hasContextBefore(content, start, medicalCodeContext) {
+			confidence += 0.35
+		}
+		if confidence == 0 {
+			continue
+		}
+		found = append(found, d.match(content, start, end, Article9Health, confidence))
+	}
+	return found
+}
+
+func (d *SpecialCategoryDetector) match(content string, start, end int, category string, confidence float64) models.Match {
+	m := newMatch(content, start, end, d.Label)
+	m.Subtype = category
+	m.Confidence = confidence
+	return m
+}
+
+// hasContextBefore checks the 50 bytes in front of start for a label
+func hasContextBefore(content string, start int, label *regexp.Regexp) bool {
+	from := start - 50
+	if from < 0 {
+		from = 0
+	}
+	return label.MatchString(content[from:start])
+}
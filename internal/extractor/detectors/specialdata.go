@@ -0,0 +1,90 @@
+package detectors
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	//go:embed data/icd10_chapters.tsv
+	icd10ChaptersTSV []byte
+
+	//go:embed data/ops_chapters.tsv
+	opsChaptersTSV []byte
+
+	//go:embed data/medications.txt
+	medicationsTXT []byte
+)
+
+type icdChapter struct {
+	First, Last string // three character categories, e.g. "A00" and "B99"
+	Title       string
+}
+
+type medicalVocabulary struct {
+	icdChapters []icdChapter
+	opsChapters map[string]string
+	medications *regexp.Regexp
+}
+
+var (
+	medicalVocabularyOnce sync.Once
+	loadedMedical         *medicalVocabulary
+)
+
+func getMedicalVocabulary() *medicalVocabulary {
+	medicalVocabularyOnce.Do(func() {
+		v := &medicalVocabulary{opsChapters: make(map[string]string)}
+
+		for _, fields := range readTSV(icd10ChaptersTSV) {
+			if len(fields) >= 3 {
+				v.icdChapters = append(v.icdChapters, icdChapter{First: fields[0], Last: fields[1], Title: fields[2]})
+			}
+		}
+		for _, fields := range readTSV(opsChaptersTSV) {
+			if len(fields) >= 2 {
+				v.opsChapters[fields[0]] = fields[1]
+			}
+		}
+
+		// One alternation for all names, longest first so "Levothyroxine" wins over "Levothyroxin"
+		var names []string
+		for name := range parseWordList(medicationsTXT) {
+			names = append(names, regexp.QuoteMeta(name))
+		}
+		sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+		v.medications = regexp.MustCompile(`(?i)(?:` + strings.Join(names, "|") + `)`)
+
+		loadedMedical = v
+	})
+	return loadedMedical
+}
+
+// icdChapterFor returns the chapter title of a three character ICD-10 category
+func (v *medicalVocabulary) icdChapterFor(category string) (string, bool) {
+	for _, c := range v.icdChapters {
+		if category >= c.First && category <= c.Last {
+			return c.Title, true
+		}
+	}
+	return "", false
+}
+
+// readTSV returns the tab separated fields of all non-comment lines
+func readTSV(data []byte) [][]string {
+	var out [][]string
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		out = append(out, strings.Split(line, "\t"))
+	}
+	return out
+}
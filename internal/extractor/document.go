@@ -0,0 +1,114 @@
+package extractor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/models"
+)
+
+// maxDocumentText caps the extracted text kept for AI analysis. Detection
+// still runs on the whole file; only the text beyond the cap is not retained.
+const maxDocumentText = 8 << 20
+
+// Document layouts, which decide how offsets are described to the user
+const (
+	LayoutText  = "text"  // Plain lines
+	LayoutTable = "table" // Tab-separated rows, one Section per sheet
+	LayoutPages = "pages" // Lines of text, one Section per page
+)
+
+// Section is a named part of a document (a sheet or a page) starting at Start
+type Section struct {
+	Name  string
+	Start int64
+}
+
+// Document is the result of scanning one file: the extracted text and the
+// detector matches, whose offsets index into Text
+type Document struct {
+	Text      string
+	Matches   []models.Match
+	Truncated bool // Text stops at maxDocumentText, later matches have no text
+	Layout    string
+	Sections  []Section
+}
+
+// Locate describes where offset lies: the 1-based line (row for tables) and
+// a readable location such as "line 12", "Sheet1!B3" or "page 2, line 5".
+// Offsets beyond the retained text cannot be located and return 0, "".
+func (d *Document) Locate(offset int64) (int, string) {
+	if offset < 0 || offset > int64(len(d.Text)) {
+		return 0, ""
+	}
+
+	section := Section{}
+	for _, sec := range d.Sections {
+		if sec.Start > offset {
+			break
+		}
+		section = sec
+	}
+
+	before := d.Text[section.Start:offset]
+	line := strings.Count(before, "\n") + 1
+
+	switch d.Layout {
+	case LayoutTable:
+		lineStart := strings.LastIndexByte(before, '\n') + 1
+		column := strings.Count(before[lineStart:], "\t")
+		return line, fmt.Sprintf("%s!%s%d", section.Name, columnName(column), line)
+	case LayoutPages:
+		return line, fmt.Sprintf("%s, line %d", section.Name, line)
+	default:
+		return line, fmt.Sprintf("line %d", line)
+	}
+}
+
+// columnName converts a 0-based column index to a spreadsheet name (0 -> A, 26 -> AA)
+func columnName(i int) string {
+	name := ""
+	for i >= 0 {
+		name = string(rune('A'+i%26)) + name
+		i = i/26 - 1
+	}
+	return name
+}
+
+// textBuffer collects extracted text up to maxDocumentText
+type textBuffer struct {
+	data      []byte
+	truncated bool
+	sections  []Section
+}
+
+// Section starts a new named part at the current end of the text
+func (b *textBuffer) Section(name string) {
+	b.sections = append(b.sections, Section{Name: name, Start: int64(len(b.data))})
+}
+
+func (b *textBuffer) Write(p []byte) {
+	if b.truncated {
+		return
+	}
+	if room := maxDocumentText - len(b.data); len(p) > room {
+		p = p[:room]
+		b.truncated = true
+	}
+	b.data = append(b.data, p...)
+}
+
+func (b *textBuffer) WriteString(s string) {
+	b.Write([]byte(s))
+}
+
+// Document returns the collected text together with the matches
+func (b *textBuffer) Document(layout string, matches []models.Match) *Document {
+	return &Document{
+		Text:      string(b.data),
+		Matches:   matches,
+		Truncated: b.truncated,
+		Layout:    layout,
+		Sections:  b.sections,
+	}
+}
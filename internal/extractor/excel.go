@@ -1,16 +1,20 @@
 package extractor
 
 import (
+	"context"
 	"io"
 
+	"github.com/digimosa/ai-gdpr-scan/internal/extractor/detectors"
 	"github.com/digimosa/ai-gdpr-scan/internal/models"
 	"github.com/xuri/excelize/v2"
 )
 
 // ExcelScanner implements scanning for Excel files
-type ExcelScanner struct{}
+type ExcelScanner struct {
+	Detectors []detectors.Detector
+}
 
-func (s *ExcelScanner) Scan(reader io.Reader) ([]models.Match, error) {
+func (s *ExcelScanner) Scan(ctx context.Context, reader io.Reader) (*Document, error) {
 	// Excelize supports reading from a reader
 	f, err := excelize.OpenReader(reader)
 	if err != nil {
@@ -19,6 +23,11 @@ func (s *ExcelScanner) Scan(reader io.Reader) ([]models.Match, error) {
 	defer f.Close()
 
 	var matches []models.Match
+	var text textBuffer
+
+	// Offsets refer to a tab-separated rendering of the workbook (cells joined by
+	// tabs, rows by newlines), so neighbouring cells are also close by offset.
+	offset := int64(0)
 
 	// Get all sheet names
 	for _, sheet := range f.GetSheetList() {
@@ -27,10 +36,13 @@ func (s *ExcelScanner) Scan(reader io.Reader) ([]models.Match, error) {
 		if err != nil {
 			continue
 		}
+		text.Section(sheet)
 
-		rowIdx := 0
 		for rows.Next() {
-			rowIdx++
+			if err := ctx.Err(); err != nil {
+				rows.Close()
+				return nil, err
+			}
 			row, err := rows.Columns()
 			if err != nil {
 				break
@@ -39,25 +51,31 @@ func (s *ExcelScanner) Scan(reader io.Reader) ([]models.Match, error) {
 			// Check each cell using centralized regex checks
 			// We treat each cell as a small "content" block
 			for colIdx, cellValue := range row {
+				// Avoid infinite loops or massive memory usage on extremely wide sheets
+				if colIdx > 1000 {
+					break
+				}
 				if cellValue == "" {
+					text.WriteString("\t")
+					offset++
 					continue
 				}
 
 				// Run checks
-				findings := runRegexChecks(cellValue, int64(rowIdx))
+				findings := runRegexChecks(cellValue, offset, s.Detectors)
 
 				// For excel, the snippet is the cell content itself usually,
 				// but runRegexChecks generates snippets based on its input.
 				// Since input is just cellValue, snippet == cellValue (mostly).
 				matches = append(matches, findings...)
 
-				// Avoid infinite loops or massive memory usage on extremely wide sheets
-				if colIdx > 1000 {
-					break
-				}
+				text.WriteString(cellValue + "\t")
+				offset += int64(len(cellValue)) + 1
 			}
+			text.WriteString("\n")
+			offset++
 		}
 	}
 
-	return matches, nil
+	return text.Document(LayoutTable, matches), nil
 }
@@ -1,60 +1,94 @@
 package extractor
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 	"strings"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/config"
+	"github.com/digimosa/ai-gdpr-scan/internal/extractor/detectors"
 )
 
 // Factory handles creation of appropriate content scanners
-type Factory struct{}
+type Factory struct {
+	detectors []detectors.Detector
+}
 
 // NewFactory creates a new scanner factory
-func NewFactory() *Factory {
-	return &Factory{}
+func NewFactory(cfg *config.Config) *Factory {
+	return &Factory{
+		detectors: NewDetectors(cfg),
+	}
 }
 
-// GetScannerForFile returns the appropriate ContentScanner based on file extension
-func (f *Factory) GetScannerForFile(path string) (ContentScanner, string, error) {
-	ext := strings.ToLower(filepath.Ext(path))
+// NewDetectors builds the detector set used by all content scanners.
+// Detectors are stateless after construction and safe to share between workers.
+func NewDetectors(cfg *config.Config) []detectors.Detector {
+	return []detectors.Detector{
+		detectors.NewIBANDetector(),
+		detectors.NewCreditCardDetector(),
+		detectors.NewEmailDetector(),
+		detectors.NewPhoneDetector(cfg.PhoneRegion),
+		detectors.NewNameDetector(),
+		detectors.NewIdentityKeywordDetector(),
+		detectors.NewFinancialKeywordDetector(),
+		detectors.NewOfficialIDKeywordDetector(),
+		detectors.NewSensitiveKeywordDetector(),
+		detectors.NewSpecialCategoryDetector(),
+		detectors.NewSecretDetector(),
+	}
+}
+
+var (
+	// ErrUnsupported is returned for extensions skipped by policy, see IsSupported
+	ErrUnsupported = errors.New("unsupported file extension")
+	// ErrBinary is returned for content no extractor reads
+	ErrBinary = errors.New("binary content")
+)
 
+// GetScannerForFile returns the appropriate ContentScanner for the content
+// of the file, whatever its extension says. Files with an unsupported
+// extension or binary content yield an error.
+func (f *Factory) GetScannerForFile(path string) (ContentScanner, FileType, error) {
+	ext := strings.ToLower(filepath.Ext(path))
 	if !f.IsSupported(ext) {
-		return nil, ext, fmt.Errorf("unsupported file extension: %s", ext)
+		return nil, FileType{Extension: ext}, fmt.Errorf("%w: %s", ErrUnsupported, ext)
+	}
+
+	ft, err := DetectType(path)
+	if err != nil {
+		return nil, ft, err
 	}
 
 	var scanner ContentScanner
-	switch ext {
-	case ".pdf":
-		scanner = &PDFScanner{}
-	case ".xlsx":
-		scanner = &ExcelScanner{}
+	switch kind(ft.MIME) {
+	case "pdf":
+		scanner = &PDFScanner{Detectors: f.detectors}
+	case "xlsx":
+		scanner = &ExcelScanner{Detectors: f.detectors}
+	case "text", "ole":
+		// Plain text of any kind (.txt, .csv, .log, .md, .json, .xml, ...),
+		// and legacy Office files whose text is stored uncompressed
+		scanner = &TextScanner{Detectors: f.detectors}
 	default:
-		// Default to text scanner for .txt, .csv, .log, .md, .go, etc.
-		scanner = &TextScanner{}
+		return nil, ft, ErrBinary
 	}
 
-	return scanner, ext, nil
+	return scanner, ft, nil
 }
 
-// IsSupported checks if the file extension is supported for scanning
+// IsSupported reports whether files with the extension are scanned at all.
+// Source code and scripts are skipped by policy; binary content is detected
+// by GetScannerForFile whatever the extension.
 func (f *Factory) IsSupported(ext string) bool {
 	switch ext {
-	// Block strict binaries / media
-	case ".exe", ".dll", ".so", ".dylib", ".bin", ".class", ".pyc":
-		return false
 	// Block strict source code (if user wants to skip logic, keep data/structure)
 	// User requested to skip "where only code is in"
 	case ".css", ".js", ".ts", ".go", ".c", ".cpp", ".h", ".hpp", ".java", ".py", ".rb", ".php", ".cs", ".rs", ".swift", ".kt", ".dart":
 		return false
 	case ".sh", ".bash", ".zsh", ".bat", ".cmd", ".ps1":
 		return false
-	case ".jpg", ".jpeg", ".png", ".gif", ".bmp", ".tiff", ".webp":
-		return false
-	case ".mp3", ".mp4", ".wav", ".avi", ".mov", ".mkv":
-		return false
-	case ".zip", ".tar", ".gz", ".rar", ".7z", ".iso":
-		return false
-	// Allow things that might contain data: .txt, .csv, .log, .json, .xml, .yaml, .md, .pdf, .xlsx, .docx
 	default:
 		return true
 	}
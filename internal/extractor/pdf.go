@@ -2,17 +2,22 @@ package extractor
 
 import (
 	"bytes"
+	"context"
+	"fmt"
 	"io"
 	"os"
 
+	"github.com/digimosa/ai-gdpr-scan/internal/extractor/detectors"
 	"github.com/digimosa/ai-gdpr-scan/internal/models"
 	"github.com/ledongthuc/pdf"
 )
 
 // PDFScanner implements scanning for PDF files
-type PDFScanner struct{}
+type PDFScanner struct {
+	Detectors []detectors.Detector
+}
 
-func (s *PDFScanner) Scan(reader io.Reader) ([]models.Match, error) {
+func (s *PDFScanner) Scan(ctx context.Context, reader io.Reader) (*Document, error) {
 	// ledongthuc/pdf requires an io.ReaderAt and size.
 	// Since we are passed an io.Reader, we might need to read it into a buffer
 	// or modify the interface to accept a file path or require ReaderAt.
@@ -50,12 +55,19 @@ func (s *PDFScanner) Scan(reader io.Reader) ([]models.Match, error) {
 	}
 
 	var matches []models.Match
+	var text textBuffer
 
 	// Iterate through pages
-	// Note: ledongthuc/pdf can be slow on large docs, consider timeouts in calling code
+	// ledongthuc/pdf can be slow on large docs, so cancellation is checked per page
 	totalPages := doc.NumPage()
 
+	// Offsets refer to the concatenated plain text of all pages
+	offset := int64(0)
+
 	for i := 1; i <= totalPages; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		page := doc.Page(i)
 		if page.V.IsNull() {
 			continue
@@ -66,12 +78,15 @@ func (s *PDFScanner) Scan(reader io.Reader) ([]models.Match, error) {
 			continue // Skip page on error
 		}
 
+		text.Section(fmt.Sprintf("page %d", i))
+
 		// Use the centralized regex checks
-		// Offset is set to page number for PDF context
-		// We could try to map byte offset within page, but page number is more useful
-		pageFindings := runRegexChecks(content, int64(i))
+		pageFindings := runRegexChecks(content, offset, s.Detectors)
 		matches = append(matches, pageFindings...)
+
+		text.WriteString(content + "\n")
+		offset += int64(len(content)) + 1
 	}
 
-	return matches, nil
+	return text.Document(LayoutPages, matches), nil
 }
@@ -0,0 +1,190 @@
+package extractor
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MIME types the extractors and the mismatch check tell apart
+const (
+	MIMEPDF     = "application/pdf"
+	MIMEXLSX    = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	MIMEDOCX    = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	MIMEPPTX    = "application/vnd.openxmlformats-officedocument.presentationml.presentation"
+	MIMEODF     = "application/vnd.oasis.opendocument"
+	MIMEZIP     = "application/zip"
+	MIMEOLE     = "application/x-ole-storage" // Office 97-2003 documents
+	MIMEExec    = "application/x-executable"
+	MIMESQLite  = "application/vnd.sqlite3"
+	MIMEUnknown = "application/octet-stream"
+)
+
+// sniffLen is how much of a file is read to detect its type
+const sniffLen = 8192
+
+// FileType is the type of a file as detected from its content
+type FileType struct {
+	Extension string // Lower-case, with dot; may be empty
+	MIME      string // From the content, without parameters
+	Mismatch  bool   // The extension belongs to a different type
+}
+
+// magic holds signatures net/http does not know
+var magic = []struct {
+	prefix string
+	mime   string
+}{
+	{"%PDF-", MIMEPDF},
+	{"\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1", MIMEOLE},
+	{"\x7fELF", MIMEExec},
+	{"MZ", MIMEExec},
+	{"\xcf\xfa\xed\xfe", MIMEExec}, // Mach-O 64-bit
+	{"\xca\xfe\xba\xbe", MIMEExec}, // Mach-O universal or Java class
+	{"SQLite format 3\x00", MIMESQLite},
+	{"7z\xbc\xaf\x27\x1c", "application/x-7z-compressed"},
+}
+
+// extensionKinds maps extensions to the kind of content they promise, see
+// kind. Unlisted extensions, such as .dat, promise nothing.
+var extensionKinds = map[string]string{
+	".pdf": "pdf", ".xlsx": "xlsx", ".docx": "docx", ".pptx": "pptx",
+	".doc": "ole", ".xls": "ole", ".ppt": "ole", ".msg": "ole",
+	".odt": "odf", ".ods": "odf", ".odp": "odf",
+	".zip": "zip", ".jar": "zip",
+	".txt": "text", ".csv": "text", ".tsv": "text", ".log": "text", ".md": "text",
+	".json": "text", ".xml": "text", ".yaml": "text", ".yml": "text", ".html": "text",
+	".htm": "text", ".ini": "text", ".conf": "text", ".sql": "text", ".eml": "text", ".vcf": "text",
+	".jpg": "image", ".jpeg": "image", ".png": "image", ".gif": "image", ".bmp": "image",
+	".webp": "image", ".tiff": "image",
+	".exe": "exec", ".dll": "exec", ".so": "exec", ".dylib": "exec",
+	".db": "sqlite", ".sqlite": "sqlite",
+}
+
+// kind groups MIME types into what the mismatch check compares
+func kind(mime string) string {
+	switch {
+	case mime == MIMEPDF:
+		return "pdf"
+	case mime == MIMEXLSX:
+		return "xlsx"
+	case mime == MIMEDOCX:
+		return "docx"
+	case mime == MIMEPPTX:
+		return "pptx"
+	case strings.HasPrefix(mime, MIMEODF):
+		return "odf"
+	case mime == MIMEZIP:
+		return "zip"
+	case mime == MIMEOLE:
+		return "ole"
+	case mime == MIMEExec:
+		return "exec"
+	case mime == MIMESQLite:
+		return "sqlite"
+	case strings.HasPrefix(mime, "text/"):
+		return "text"
+	case strings.HasPrefix(mime, "image/"):
+		return "image"
+	}
+	return ""
+}
+
+// DetectType reads the start of a file to tell its type. Zip files are
+// opened to tell office formats apart.
+func DetectType(path string) (FileType, error) {
+	ft := FileType{Extension: strings.ToLower(filepath.Ext(path))}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return ft, err
+	}
+	defer f.Close()
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return ft, err
+	}
+	head = head[:n]
+
+	ft.MIME = sniff(head)
+	if ft.MIME == MIMEZIP {
+		if info, err := f.Stat(); err == nil {
+			ft.MIME = sniffZip(f, info.Size())
+		}
+	}
+
+	if expected, ok := extensionKinds[ft.Extension]; ok {
+		ft.Mismatch = expected != kind(ft.MIME)
+	}
+	return ft, nil
+}
+
+func sniff(head []byte) string {
+	for _, m := range magic {
+		if bytes.HasPrefix(head, []byte(m.prefix)) {
+			return m.mime
+		}
+	}
+	if len(head) == 0 {
+		return "text/plain"
+	}
+	mime := http.DetectContentType(head)
+	if i := strings.IndexByte(mime, ';'); i >= 0 {
+		mime = mime[:i]
+	}
+	// A few control bytes make net/http call text binary; the text scanner
+	// blanks them out
+	if mime == MIMEUnknown && mostlyText(head) {
+		return "text/plain"
+	}
+	return mime
+}
+
+// mostlyText reports whether at least 90% of the bytes are printable, see
+// sanitizeBytes
+func mostlyText(data []byte) bool {
+	printable := 0
+	for _, b := range data {
+		if (b >= 32 && b <= 126) || b == 9 || b == 10 || b == 13 || b > 127 {
+			printable++
+		}
+	}
+	return printable*10 >= len(data)*9
+}
+
+// sniffZip tells office documents from other zip archives by their entries
+func sniffZip(r io.ReaderAt, size int64) string {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return MIMEZIP
+	}
+	for _, f := range zr.File {
+		switch {
+		case f.Name == "xl/workbook.xml":
+			return MIMEXLSX
+		case f.Name == "word/document.xml":
+			return MIMEDOCX
+		case f.Name == "ppt/presentation.xml":
+			return MIMEPPTX
+		case f.Name == "mimetype" && strings.HasPrefix(readEntry(f, 128), MIMEODF):
+			return readEntry(f, 128)
+		}
+	}
+	return MIMEZIP
+}
+
+func readEntry(f *zip.File, limit int64) string {
+	rc, err := f.Open()
+	if err != nil {
+		return ""
+	}
+	defer rc.Close()
+	data, _ := io.ReadAll(io.LimitReader(rc, limit))
+	return strings.TrimSpace(string(data))
+}
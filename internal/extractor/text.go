@@ -1,24 +1,31 @@
 package extractor
 
 import (
+	"context"
 	"io"
 	"regexp"
+	"strings"
 
+	"github.com/digimosa/ai-gdpr-scan/internal/config"
 	"github.com/digimosa/ai-gdpr-scan/internal/extractor/detectors"
 	"github.com/digimosa/ai-gdpr-scan/internal/models"
 )
 
-// Scanner defines the interface for content scanning
+// Scanner defines the interface for content scanning. Scan stops with the
+// context's error when ctx is cancelled.
 type ContentScanner interface {
-	Scan(reader io.Reader) ([]models.Match, error)
+	Scan(ctx context.Context, reader io.Reader) (*Document, error)
 }
 
 // TextScanner implements scanning for plain text files
 // It now uses chunk-based reading to handle binary/mixed files robustly.
-type TextScanner struct{}
+type TextScanner struct {
+	Detectors []detectors.Detector
+}
 
-func (s *TextScanner) Scan(reader io.Reader) ([]models.Match, error) {
+func (s *TextScanner) Scan(ctx context.Context, reader io.Reader) (*Document, error) {
 	var matches []models.Match
+	var text textBuffer
 
 	// Use a 64KB buffer for chunk-based reading
 	const bufSize = 64 * 1024
@@ -32,6 +39,9 @@ func (s *TextScanner) Scan(reader io.Reader) ([]models.Match, error) {
 	offset := int64(0)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		n, err := reader.Read(buf)
 		if n > 0 {
 			// Combine overlap from previous chunk with current read
@@ -72,9 +82,13 @@ func (s *TextScanner) Scan(reader io.Reader) ([]models.Match, error) {
 			}
 
 			// Run all checks on this chunk
-			foundMatches := runRegexChecks(chunkStr, chunkStartOffset)
+			foundMatches := runRegexChecks(chunkStr, chunkStartOffset, s.Detectors)
 			matches = append(matches, foundMatches...)
 
+			// Keep the text for AI analysis; offsets stay file offsets because
+			// sanitizing and tag stripping preserve length
+			text.WriteString(stripXMLTags(chunkStr[len(overlap):]))
+
 			// Prepare overlap for next iteration
 			if n >= overlapSize {
 				overlap = make([]byte, overlapSize)
@@ -96,7 +110,7 @@ func (s *TextScanner) Scan(reader io.Reader) ([]models.Match, error) {
 		}
 	}
 
-	return matches, nil
+	return text.Document(LayoutText, matches), nil
 }
 
 // sanitizeBytes replaces non-printable characters with spaces,
@@ -124,27 +138,18 @@ func sanitizeBytes(data []byte) []byte {
 var xmlTagRegex = regexp.MustCompile(`<[^>]*>`)
 
 func stripXMLTags(content string) string {
-	return xmlTagRegex.ReplaceAllString(content, " ") // Replace with space to maintain word boundaries
+	// Replace with spaces of equal length to maintain word boundaries and match offsets
+	return xmlTagRegex.ReplaceAllStringFunc(content, func(tag string) string {
+		return strings.Repeat(" ", len(tag))
+	})
 }
 
-func runRegexChecks(content string, baseOffset int64) []models.Match {
+func runRegexChecks(content string, baseOffset int64, detectorsList []detectors.Detector) []models.Match {
 	var matches []models.Match
 
 	// Filter out XML tags to avoid false positives in code/metadata
 	cleanContent := stripXMLTags(content)
 
-	detectorsList := []detectors.Detector{
-		detectors.NewIBANDetector(),
-		detectors.NewCreditCardDetector(),
-		detectors.NewEmailDetector(),
-		detectors.NewPhoneDetector(),
-		detectors.NewNameDetector(),
-		detectors.NewIdentityKeywordDetector(),
-		detectors.NewFinancialKeywordDetector(),
-		detectors.NewOfficialIDKeywordDetector(),
-		detectors.NewSensitiveKeywordDetector(),
-	}
-
 	for _, d := range detectorsList {
 		found := d.Detect(cleanContent)
 		for i := range found {
@@ -199,5 +204,5 @@ func getSnippet(fullText, match string) string {
 // Helper function to create scanners based on file type
 func NewScannerForFile(path string) (ContentScanner, error) {
 	// Logic now handled in factory.go
-	return &TextScanner{}, nil
+	return &TextScanner{Detectors: NewDetectors(config.DefaultConfig())}, nil
 }
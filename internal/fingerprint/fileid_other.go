@@ -0,0 +1,11 @@
+//go:build !unix
+
+package fingerprint
+
+import "os"
+
+// fileID is not available on this platform; fingerprints then rely on size,
+// modification time and content hash
+func fileID(info os.FileInfo) (uint64, uint64) {
+	return 0, 0
+}
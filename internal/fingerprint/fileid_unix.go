@@ -0,0 +1,17 @@
+//go:build unix
+
+package fingerprint
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileID returns the device and inode number of a file
+func fileID(info os.FileInfo) (uint64, uint64) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0
+	}
+	return uint64(st.Dev), uint64(st.Ino)
+}
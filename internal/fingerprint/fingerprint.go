@@ -0,0 +1,51 @@
+// Package fingerprint identifies file versions, so incremental scans can
+// tell unchanged files from modified ones without extracting them.
+package fingerprint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/models"
+)
+
+// Of returns the fingerprint of a file without its content hash
+func Of(info os.FileInfo) models.Fingerprint {
+	dev, ino := fileID(info)
+	return models.Fingerprint{
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		Device:  dev,
+		Inode:   ino,
+	}
+}
+
+// Hash returns the hex SHA-256 of a file's content
+func Hash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// SameMetadata reports whether two fingerprints agree in size, modification
+// time and, where both are known, device and inode. A file replaced by a
+// copy with the same times gets a new inode.
+func SameMetadata(a, b models.Fingerprint) bool {
+	if a.Size != b.Size || !a.ModTime.Equal(b.ModTime) {
+		return false
+	}
+	if a.Inode != 0 && b.Inode != 0 {
+		return a.Inode == b.Inode && a.Device == b.Device
+	}
+	return true
+}
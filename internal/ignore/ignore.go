@@ -0,0 +1,160 @@
+// Package ignore matches paths against gitignore-style patterns, as used by
+// the -include/-exclude options and per-directory .gdprignore files.
+//
+// Supported syntax: blank lines and lines starting with # are ignored, a
+// leading ! re-includes a path, a trailing / matches directories only, a
+// pattern containing / is relative to the directory of its rules while one
+// without matches at any depth, and *, ?, [...] and ** work as in git.
+package ignore
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Rules is an ordered list of patterns relative to a base directory. The
+// last matching pattern decides.
+type Rules struct {
+	Base     string // Directory the patterns are relative to
+	patterns []pattern
+}
+
+type pattern struct {
+	segments []string
+	negate   bool
+	dirOnly  bool
+}
+
+// New parses patterns relative to base
+func New(base string, lines []string) *Rules {
+	r := &Rules{Base: base}
+	for _, line := range lines {
+		if p, ok := parse(line); ok {
+			r.patterns = append(r.patterns, p)
+		}
+	}
+	return r
+}
+
+// Load reads the rules file name in dir. A missing file yields nil rules.
+func Load(dir, name string) (*Rules, error) {
+	file := filepath.Join(dir, name)
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return New(dir, lines), nil
+}
+
+// Empty reports whether r has no patterns
+func (r *Rules) Empty() bool {
+	return r == nil || len(r.patterns) == 0
+}
+
+func parse(line string) (pattern, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return pattern{}, false
+	}
+
+	var p pattern
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\#`) || strings.HasPrefix(line, `\!`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return pattern{}, false
+	}
+
+	// Without an inner slash the pattern matches at any depth
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	p.segments = strings.Split(line, "/")
+	if !anchored {
+		p.segments = append([]string{"**"}, p.segments...)
+	}
+	return p, true
+}
+
+// Match reports whether path, a file or directory below Base, is ignored
+// by the rules (ignored) and whether any pattern matched at all (matched).
+// A path matched only by a ! pattern is matched but not ignored.
+func (r *Rules) Match(p string, isDir bool) (ignored, matched bool) {
+	if r.Empty() {
+		return false, false
+	}
+	rel, err := filepath.Rel(r.Base, p)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false, false
+	}
+	segments := strings.Split(filepath.ToSlash(rel), "/")
+
+	for _, pat := range r.patterns {
+		if pat.dirOnly && !isDir {
+			continue
+		}
+		if matchSegments(pat.segments, segments) {
+			ignored, matched = !pat.negate, true
+		}
+	}
+	return ignored, matched
+}
+
+// MatchAny reports whether the file p or one of its parent directories
+// below Base is matched by a pattern that is not negated, e.g. for include
+// lists where "reports/" selects every file in that directory
+func (r *Rules) MatchAny(p string) bool {
+	if ignored, _ := r.Match(p, false); ignored {
+		return true
+	}
+	for dir := filepath.Dir(p); dir != r.Base && dir != "." && dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if ignored, _ := r.Match(dir, true); ignored {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		// ** matches zero or more directories
+		for i := 0; i <= len(name); i++ {
+			if matchSegments(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], name[1:])
+}
@@ -4,25 +4,176 @@ import "time"
 
 // Finding represents a single PII match found in a file
 type Finding struct {
-	Type       string  `json:"type"`              // e.g., "IBAN", "Email", "Phone"
-	Snippet    string  `json:"snippet"`           // Redacted or partial snippet for verification
-	Confidence float64 `json:"confidence"`        // 0.0 to 1.0
-	Offset     int64   `json:"offset"`            // Byte offset in file
-	Context    string  `json:"context,omitempty"` // AI explanation or surrounding context
-	ID         uint    `json:"id"`                // Database ID for feedback
-	Feedback   string  `json:"feedback"`          // "Correct", "Incorrect", "Unknown"
+	Type       string  `json:"type"`                 // e.g., "IBAN", "Email", "Phone"
+	Subtype    string  `json:"subtype,omitempty"`    // e.g., "mobile" for phone numbers
+	Snippet    string  `json:"snippet"`              // Redacted or partial snippet for verification
+	Confidence float64 `json:"confidence"`           // 0.0 to 1.0
+	Offset     int64   `json:"offset"`               // Byte offset in file
+	Line       int     `json:"line,omitempty"`       // 1-based line, or row for spreadsheets
+	Location   string  `json:"location,omitempty"`   // e.g. "line 12", "Sheet1!B3", "page 2, line 5"
+	Origin     string  `json:"origin,omitempty"`     // OriginRegex, OriginAI or OriginConfirmed
+	Unverified bool    `json:"unverified,omitempty"` // AI value that does not occur in the extracted text
+	Context    string  `json:"context,omitempty"`    // AI explanation or surrounding context
+	Excerpt    string  `json:"-"`                    // Value with surrounding text, compared by the false-positive filter
+	ID         uint    `json:"id"`                   // Database ID for feedback
+	Feedback   string  `json:"feedback"`             // "Correct", "Incorrect", "Unknown"
 }
 
+// Finding origins
+const (
+	OriginRegex     = "regex"    // Detector match not reviewed by the AI
+	OriginAI        = "ai"       // Reported by the AI without a detector match
+	OriginConfirmed = "ai+regex" // Detector match confirmed by the AI
+)
+
 // ScanResult represents the outcome of scanning a single file
 type ScanResult struct {
 	FilePath  string    `json:"file_path"`
 	FileType  string    `json:"file_type"`
+	MIMEType  string    `json:"mime_type,omitempty"`     // Detected from the content
+	Mismatch  bool      `json:"type_mismatch,omitempty"` // The extension belongs to a different type than the content
 	Size      int64     `json:"size"`
 	Findings  []Finding `json:"findings"`
-	Error     error     `json:"-"` // Internal error tracking
-	ErrorMsg  string    `json:"error,omitempty"`
-	ScanTime  time.Duration
-	Timestamp time.Time
+	RiskScore float64   `json:"risk_score"`          // 0-100, see scoring.RiskScore
+	Coverage  *Coverage `json:"coverage,omitempty"`  // Set when the file went through AI analysis
+	AIStatus  string    `json:"ai_status,omitempty"` // AIStatusAnalyzed, AIStatusRegexOnly or AIStatusFailed
+	Injection []string  `json:"injection,omitempty"` // Why the file may be a prompt injection attempt
+
+	// Document category, see Categories. Empty when no category fits.
+	Category           string  `json:"category,omitempty"`
+	CategoryConfidence float64 `json:"category_confidence,omitempty"`
+	CategorySource     string  `json:"category_source,omitempty"` // "rules", "ai" or "rules+ai"
+	Error              error   `json:"-"`                         // Internal error tracking
+	ErrorMsg           string  `json:"error,omitempty"`
+	ScanTime           time.Duration
+	Timestamp          time.Time
+
+	// Incremental scans: how the file differs from its last scan, and its
+	// current fingerprint for the file inventory
+	Change      string       `json:"change,omitempty"` // ChangeNew, ChangeModified or ChangeUnchanged
+	Fingerprint *Fingerprint `json:"-"`
+
+	// Why the file was not scanned after all, e.g. SkipBinary. Skipped
+	// results are counted in reporting.Summary.Skipped only.
+	Skipped string `json:"-"`
+}
+
+// Reasons the walker skips a path, see reporting.Summary.Skipped
+const (
+	SkipUnsupported  = "unsupported type"
+	SkipExcluded     = "exclude pattern"
+	SkipIgnoreFile   = "ignore file"
+	SkipNotIncluded  = "not included"
+	SkipHidden       = "hidden"
+	SkipTooLarge     = "too large"
+	SkipTooSmall     = "too small"
+	SkipTooOld       = "too old"
+	SkipTooNew       = "too new"
+	SkipSymlink      = "symlinked directory"
+	SkipSymlinkLoop  = "symlink loop"
+	SkipSymlinkTwice = "symlink target already walked"
+	SkipAccessError  = "access error"
+	SkipBinary       = "binary content" // Detected by the scanner, not the walker
+)
+
+// Changes of a file since its last incremental scan
+const (
+	ChangeNew       = "new"
+	ChangeModified  = "modified"
+	ChangeUnchanged = "unchanged" // Findings carried forward without extraction
+)
+
+// Fingerprint identifies the version of a file. Inode and Device are 0 on
+// platforms without them.
+type Fingerprint struct {
+	Size    int64
+	ModTime time.Time
+	Device  uint64
+	Inode   uint64
+	Hash    string // SHA-256 of the content
+}
+
+// AI analysis outcome of a file with candidates
+const (
+	AIStatusAnalyzed  = "analyzed"   // The AI reviewed the candidates (see Coverage)
+	AIStatusRegexOnly = "regex_only" // The AI was disabled or declared down; candidates are unverified
+	AIStatusFailed    = "failed"     // Requests failed; candidates are unverified
+)
+
+// Document categories assigned by the classification stage
+const (
+	CategoryCV      = "cv"
+	CategoryPayslip = "payslip"
+	CategoryInvoice = "invoice"
+	CategoryMedical = "medical_letter"
+	CategoryIDCopy  = "id_copy"
+)
+
+// Categories lists the document categories
+var Categories = []string{CategoryCV, CategoryPayslip, CategoryInvoice, CategoryMedical, CategoryIDCopy}
+
+// Coverage describes how much of a file the AI analysis actually reviewed.
+// Only chunks containing detector candidates are sent to the model.
+type Coverage struct {
+	Chunks         int  `json:"chunks"`          // Chunks containing candidates
+	ChunksAnalyzed int  `json:"chunks_analyzed"` // Chunks sent within the per-file budget
+	Candidates     int  `json:"candidates"`      // Detector matches in the file
+	CandidatesSeen int  `json:"candidates_seen"` // Matches inside analyzed chunks
+	TokensUsed     int  `json:"tokens_used"`     // Estimated prompt tokens
+	TextTruncated  bool `json:"text_truncated"`  // Extracted text exceeded the retention cap
+}
+
+// Ratio is the share of candidates reviewed by the AI (0.0 to 1.0)
+func (c *Coverage) Ratio() float64 {
+	if c.Candidates == 0 {
+		return 1
+	}
+	return float64(c.CandidatesSeen) / float64(c.Candidates)
+}
+
+// Complete reports whether every candidate was reviewed by the AI
+func (c *Coverage) Complete() bool {
+	return c.CandidatesSeen == c.Candidates
+}
+
+// AIStats counts model requests and the quality of their answers over a scan
+type AIStats struct {
+	Requests       int64 `json:"requests"`
+	InvalidJSON    int64 `json:"invalid_json"`    // Answers that were not a JSON object
+	SchemaErrors   int64 `json:"schema_errors"`   // JSON answers violating the findings schema
+	RepairAttempts int64 `json:"repair_attempts"` // Follow-up requests asking for a fixed answer
+	Repaired       int64 `json:"repaired"`        // Answers that became valid after a repair
+	Rejected       int64 `json:"rejected"`        // Chunks given up after all repairs failed
+	Dropped        int64 `json:"dropped"`         // Invalid findings left out of otherwise usable answers
+	Retries        int64 `json:"retries"`         // Requests repeated after a 5xx answer or timeout
+	BreakerTrips   int64 `json:"breaker_trips"`   // Times the backend was declared down
+	CacheHits      int64 `json:"cache_hits"`      // Chunks answered from the verdict cache
+	CacheMisses    int64 `json:"cache_misses"`
+	EmbedRequests  int64 `json:"embed_requests"` // Embeddings requests of the false-positive filter
+	Filtered       int64 `json:"filtered"`       // Candidates dropped by the false-positive filter
+
+	Models []ModelStats `json:"models,omitempty"` // Per model, see config AI routing
+}
+
+// ModelStats is the cost and latency of the requests sent to one model
+type ModelStats struct {
+	Model            string  `json:"model"`
+	Requests         int64   `json:"requests"`
+	Failures         int64   `json:"failures"`
+	Escalations      int64   `json:"escalations"` // Chunks re-checked because the small model was unsure
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	Cost             float64 `json:"cost"` // Tokens priced with config AIModelCosts
+	LatencyTotalMs   int64   `json:"latency_total_ms"`
+	LatencyMaxMs     int64   `json:"latency_max_ms"`
+}
+
+// LatencyAvgMs is the mean request latency in milliseconds
+func (m ModelStats) LatencyAvgMs() int64 {
+	if m.Requests == 0 {
+		return 0
+	}
+	return m.LatencyTotalMs / m.Requests
 }
 
 // Job represents a file to be scanned by a worker
@@ -42,11 +193,19 @@ const (
 	TypeID         FindingType = "OfficialID"
 	TypeSensitive  FindingType = "Sensitive"
 	TypeCreditCard FindingType = "CreditCard"
+	TypeCredential FindingType = "Credential"
 )
 
 type Match struct {
-	Type    FindingType
-	Snippet string
-	Value   string
-	Offset  int64
+	Type       FindingType
+	Subtype    string // Detector-specific classification, e.g. phone number type
+	Snippet    string
+	Value      string
+	Offset     int64
+	Confidence float64 // Detector score (0.0 to 1.0), 0 if the detector does not score
+
+	// Keyword marks label matches ("Kontonummer", "Birthdate") that only add context
+	Keyword bool
+	// Labels lists the nearby keywords that raised this match's confidence
+	Labels []string
 }
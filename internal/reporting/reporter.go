@@ -15,13 +15,26 @@ import (
 )
 
 type Summary struct {
-	TotalFilesScanned int64         `json:"total_files_scanned"`
-	TotalFilesWithPII int64         `json:"total_files_with_pii"`
-	TotalPIIFound     int64         `json:"total_pii_found"`
-	ScanDuration      time.Duration `json:"scan_duration"`
-	StartTime         time.Time     `json:"start_time"`
-	EndTime           time.Time     `json:"end_time"`
-	RootPath          string        `json:"root_path"`
+	TotalFilesScanned int64            `json:"total_files_scanned"`
+	TotalFilesWithPII int64            `json:"total_files_with_pii"`      // Files with personal data; credentials alone do not count
+	TotalPIIFound     int64            `json:"total_pii_found"`           // Personal data findings, without credentials
+	TotalCredentials  int64            `json:"total_credentials"`         // Credential findings, counted apart from the personal data
+	PartialAIFiles    int64            `json:"partial_ai_files"`          // Files where the AI budget did not cover every candidate
+	RegexOnlyFiles    int64            `json:"regex_only_files"`          // Files whose candidates the AI could not review
+	InjectionSuspects int64            `json:"injection_suspects"`        // Files flagged as possible prompt injection
+	TypeMismatches    int64            `json:"type_mismatches,omitempty"` // Files whose extension does not match their content
+	Categories        map[string]int64 `json:"categories,omitempty"`      // Files per document category
+	Skipped           map[string]int64 `json:"skipped,omitempty"`         // Paths the walker skipped, per reason (see models.SkipUnsupported)
+	NewFiles          int64            `json:"new_files,omitempty"`       // Incremental scans: files not in the inventory
+	ModifiedFiles     int64            `json:"modified_files,omitempty"`  // Incremental scans: files changed since their last scan
+	UnchangedFiles    int64            `json:"unchanged_files,omitempty"` // Incremental scans: files whose findings were carried forward
+	RemovedFiles      int64            `json:"removed_files,omitempty"`   // Incremental scans: inventory files deleted since their last scan
+	AI                models.AIStats   `json:"ai"`
+	Cancelled         bool             `json:"cancelled,omitempty"` // Stopped early; the totals cover the files scanned so far
+	ScanDuration      time.Duration    `json:"scan_duration"`
+	StartTime         time.Time        `json:"start_time"`
+	EndTime           time.Time        `json:"end_time"`
+	RootPath          string           `json:"root_path"`
 }
 
 type Report struct {
@@ -45,10 +58,76 @@ func (r *Report) AddResult(res models.ScanResult) {
 
 	r.Summary.TotalFilesScanned++
 	if len(res.Findings) > 0 {
-		r.Summary.TotalFilesWithPII++
-		r.Summary.TotalPIIFound += int64(len(res.Findings))
 		r.Findings = append(r.Findings, res)
 	}
+	var credentials int64
+	for _, f := range res.Findings {
+		if f.Type == string(models.TypeCredential) {
+			credentials++
+		}
+	}
+	r.Summary.TotalCredentials += credentials
+	if pii := int64(len(res.Findings)) - credentials; pii > 0 {
+		r.Summary.TotalFilesWithPII++
+		r.Summary.TotalPIIFound += pii
+	}
+	if len(res.Injection) > 0 {
+		r.Summary.InjectionSuspects++
+	}
+	if res.Mismatch {
+		r.Summary.TypeMismatches++
+	}
+	if res.Category != "" {
+		if r.Summary.Categories == nil {
+			r.Summary.Categories = make(map[string]int64)
+		}
+		r.Summary.Categories[res.Category]++
+	}
+	switch res.Change {
+	case models.ChangeNew:
+		r.Summary.NewFiles++
+	case models.ChangeModified:
+		r.Summary.ModifiedFiles++
+	case models.ChangeUnchanged:
+		r.Summary.UnchangedFiles++
+	}
+	if res.AIStatus == models.AIStatusRegexOnly || res.AIStatus == models.AIStatusFailed {
+		r.Summary.RegexOnlyFiles++
+	}
+	if res.AIStatus == models.AIStatusAnalyzed && res.Coverage != nil && !res.Coverage.Complete() {
+		r.Summary.PartialAIFiles++
+	}
+}
+
+// SetAIStats records the model request counters of the scan
+func (r *Report) SetAIStats(stats models.AIStats) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Summary.AI = stats
+}
+
+// AddSkipped counts a path the walker skipped
+func (r *Report) AddSkipped(reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Summary.Skipped == nil {
+		r.Summary.Skipped = make(map[string]int64)
+	}
+	r.Summary.Skipped[reason]++
+}
+
+// SetRemoved records the number of inventory files deleted since their last scan
+func (r *Report) SetRemoved(n int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Summary.RemovedFiles = n
+}
+
+// SetCancelled marks the report as partial
+func (r *Report) SetCancelled() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Summary.Cancelled = true
 }
 
 func (r *Report) Finalize() {
@@ -1,6 +1,7 @@
 package scanner
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -8,11 +9,31 @@ import (
 	"strings"
 	"time"
 
+	"github.com/digimosa/ai-gdpr-scan/internal/ai"
+	"github.com/digimosa/ai-gdpr-scan/internal/extractor"
+	"github.com/digimosa/ai-gdpr-scan/internal/extractor/detectors"
 	"github.com/digimosa/ai-gdpr-scan/internal/models"
+	"github.com/digimosa/ai-gdpr-scan/internal/scoring"
 )
 
-// scanFile implements the tiered scanning logic
-func (s *Scanner) scanFile(path string) models.ScanResult {
+// FileStages holds the output of each pipeline stage for one file
+type FileStages struct {
+	Regex    []models.Match    // Detector matches
+	Scored   []models.Match    // Matches after keyword scoring
+	Filtered []models.Match    // Scored matches kept by the false-positive filter
+	Result   models.ScanResult // Final result, after AI analysis when enabled
+}
+
+// ScanFileStages scans a single file outside the worker pool and keeps the
+// intermediate stages, for evaluation against labelled files
+func (s *Scanner) ScanFileStages(path string) FileStages {
+	var stages FileStages
+	stages.Result = s.scanFile(path, &stages)
+	return stages
+}
+
+// scanFile implements the tiered scanning logic. stages is optional.
+func (s *Scanner) scanFile(path string, stages *FileStages) models.ScanResult {
 	start := time.Now()
 	res := models.ScanResult{
 		FilePath:  path,
@@ -28,17 +49,40 @@ func (s *Scanner) scanFile(path string) models.ScanResult {
 	}
 	res.Size = info.Size()
 
-	// Tier 1: Fast Filter (Extensions) via Factory
-	scanner, ext, err := s.scannerFactory.GetScannerForFile(path)
+	// Tier 1: Fast Filter (content type) via Factory
+	scanner, ft, err := s.scannerFactory.GetScannerForFile(path)
+	res.FileType = ft.Extension
+	res.MIMEType = ft.MIME
+	res.Mismatch = ft.Mismatch
+	if errors.Is(err, extractor.ErrBinary) {
+		res.Skipped = models.SkipBinary
+		if s.cfg.Verbose {
+			log.Printf("[SKIP] %s: %s (%s)", path, models.SkipBinary, ft.MIME)
+		}
+		return res
+	}
+	if errors.Is(err, extractor.ErrUnsupported) {
+		// Skipped from processing, but not counted as an application error
+		return res
+	}
 	if err != nil {
-		// Unsupported extension or other error (e.g. factory rejected it)
-		// We just skip it from processing but don't count it as an application error unless path access failed
+		res.Error = err
+		res.ErrorMsg = fmt.Sprintf("failed to detect file type: %v", err)
+		return res
+	}
+	if ft.Mismatch && s.cfg.Verbose {
+		log.Printf("[WARN] %s: extension %s does not match content %s", path, ft.Extension, ft.MIME)
+	}
+
+	// Stored scans keep the file inventory current; incremental ones skip
+	// extraction for files unchanged since their last scan
+	if stages == nil && s.ScanModelID != 0 && s.checkInventory(path, info, &res) {
+		res.ScanTime = time.Since(start)
 		return res
 	}
-	res.FileType = ext
 
 	if s.cfg.Verbose {
-		log.Printf("[SCAN] scanning file: %s (%s)", path, ext)
+		log.Printf("[SCAN] scanning file: %s (%s)", path, ft.MIME)
 	}
 
 	// Tier 2: Heuristic Scan
@@ -53,7 +97,7 @@ func (s *Scanner) scanFile(path string) models.ScanResult {
 	}
 	defer file.Close()
 
-	matches, err := scanner.Scan(file)
+	doc, err := scanner.Scan(s.ctx, file)
 	if err != nil {
 		res.Error = err
 		res.ErrorMsg = fmt.Sprintf("scan failed: %v", err)
@@ -63,103 +107,398 @@ func (s *Scanner) scanFile(path string) models.ScanResult {
 		return res
 	}
 
+	matches := doc.Matches
 	if s.cfg.Verbose && len(matches) > 0 {
 		log.Printf("[MATCH] %s: found %d potential regex matches", path, len(matches))
 	}
 
+	// Tier 3: Scoring - keywords become context for nearby values
+	if stages != nil {
+		stages.Regex = append([]models.Match(nil), matches...)
+	}
+	matches = s.scorer.Score(matches)
+	if stages != nil {
+		stages.Scored = append([]models.Match(nil), matches...)
+	}
+
+	scored := matches
+
+	// Tier 4: Drop candidates that resemble reviewed false positives
+	matches = s.filterFalsePositives(path, matches)
+	if stages != nil {
+		stages.Filtered = append([]models.Match(nil), matches...)
+	}
+
+	// Credentials are reported as detected and never sent to the model
+	var credentials []models.Match
+	matches, credentials = splitCredentials(matches)
+	for _, m := range credentials {
+		res.Findings = append(res.Findings, findingFromMatch(m))
+	}
+
 	// Optimization: Skip individual snippet validation to reduce AI calls
 	// Instead, send the aggregated context once for full analysis if any regex matches are found.
 	if len(matches) > 0 {
-		if s.cfg.DisableAI {
+		if s.cfg.DisableAI || s.aiClient == nil || !s.aiClient.Available() {
 			// Just add regex matches directly
 			for _, m := range matches {
-				res.Findings = append(res.Findings, models.Finding{
-					Type:       string(m.Type),
-					Snippet:    m.Snippet,
-					Confidence: 0.5, // Regex only confidence
-					Offset:     m.Offset,
-				})
+				res.Findings = append(res.Findings, findingFromMatch(m))
 			}
+			res.AIStatus = models.AIStatusRegexOnly
 		} else {
-			s.performAIAnalysis(path, matches, &res)
+			s.performAIAnalysis(path, doc, matches, &res)
+			if res.Error != nil {
+				return res
+			}
 		}
 	}
 
+	s.classifyDocument(path, doc, scored, &res)
+
+	for i := range res.Findings {
+		f := &res.Findings[i]
+		if f.Unverified {
+			continue
+		}
+		f.Line, f.Location = doc.Locate(f.Offset)
+		// AI findings hold the bare value; give them the context of a match
+		if f.Excerpt == "" && f.Offset >= 0 && int(f.Offset)+len(f.Snippet) <= len(doc.Text) {
+			f.Excerpt = detectors.Snippet(doc.Text, int(f.Offset), int(f.Offset)+len(f.Snippet))
+		}
+	}
+
+	res.RiskScore = scoring.RiskScore(res.Findings)
 	res.ScanTime = time.Since(start)
 	return res
 }
 
-func (s *Scanner) performAIAnalysis(path string, matches []models.Match, res *models.ScanResult) {
+// performAIAnalysis splits the document into overlapping chunks and sends
+// every chunk that contains candidates to the AI, together with those
+// candidates. Candidates outside the per-file budget are kept as regex findings.
+func (s *Scanner) performAIAnalysis(path string, doc *extractor.Document, matches []models.Match, res *models.ScanResult) {
 	if s.cfg.Verbose {
-		log.Printf("[AI] file %s has %d potential matches, sending for bulk analysis...", path, len(matches))
+		log.Printf("[AI] file %s has %d potential matches, sending for chunked analysis...", path, len(matches))
 	}
 
-	// Construct context from all regex matches
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("File: %s\nPotential PII Context:\n", filepath.Base(path)))
+	// Types the routing policy trusts to the detectors never reach a model
+	router := s.aiClient.Router
+	matches, skipped := router.Skip(matches)
+	for _, m := range skipped {
+		f := findingFromMatch(m)
+		f.Context = "Not sent to the AI (routing policy)"
+		res.Findings = append(res.Findings, f)
+	}
+	if len(matches) == 0 {
+		return
+	}
 
-	// Limit the number of snippets sent to avoid context window explosion
-	limit := 50
-	if len(matches) < limit {
-		limit = len(matches)
+	chunks := ai.SplitChunks(doc.Text, s.cfg.AIChunkTokens, s.cfg.AIChunkOverlap)
+	perChunk, outside := assignToChunks(chunks, matches)
+
+	// One request per chunk and model its candidates are routed to
+	var requests []chunkRoute
+	for i, m := range perChunk {
+		for _, group := range router.Split(m) {
+			requests = append(requests, chunkRoute{index: i, group: group})
+		}
 	}
 
-	for i := 0; i < limit; i++ {
-		m := matches[i]
-		sb.WriteString(fmt.Sprintf("MATCH[%d]: Type=%s Snippet='%s' Offset=%d\n", i, m.Type, m.Snippet, m.Offset))
+	cov := &models.Coverage{
+		Chunks:        len(requests),
+		Candidates:    len(matches),
+		TextTruncated: doc.Truncated,
 	}
-	fullContext := sb.String()
+	res.Coverage = cov
 
-	if s.cfg.Verbose {
-		log.Printf("[AI-DEBUG] Prepared context for %s (%d chars). Sending to Ollama...", path, len(fullContext))
+	var pseudo *pseudonymizer
+	if s.cfg.AIPseudonymize {
+		pseudo = newPseudonymizer()
 	}
 
-	// One Single AI Call per file with interesting regex hits
-	// Extract unique finding types for prompt customization
-	uniqueTypes := make(map[models.FindingType]bool)
-	typeList := []models.FindingType{}
-	for _, m := range matches {
-		if !uniqueTypes[m.Type] {
-			uniqueTypes[m.Type] = true
-			typeList = append(typeList, m.Type)
+	// Matches without text (beyond the retention cap) cannot be verified
+	unverified := outside
+	var aiFindings []reconciled
+	var suspicious []models.Match
+	var aiErr error
+
+	for _, r := range requests {
+		i, chunk, chunkMatches := r.index, chunks[r.index], r.group.Matches
+
+		req := buildChunkRequest(path, i, len(chunks), chunk, chunkMatches, pseudo)
+		req.Model = r.group.Model
+		tokens := req.PromptTokens()
+		overBudget := cov.ChunksAnalyzed >= s.cfg.AIMaxChunks || cov.TokensUsed+tokens > s.cfg.AIMaxTokens
+		if aiErr != nil || overBudget {
+			unverified = append(unverified, chunkMatches...)
+			continue
+		}
+
+		if s.cfg.Verbose {
+			log.Printf("[AI-DEBUG] %s: chunk %d/%d (%d candidates, ~%d tokens). Sending to %s/%s...", path, i+1, len(chunks), len(chunkMatches), tokens, s.aiClient.Provider.Name(), req.Model)
+		}
+
+		found, err := s.aiClient.AnalyzeChunk(s.ctx, req)
+		if err != nil {
+			if s.cfg.Verbose {
+				log.Printf("[AI-FULL] Error analyzing file %s: %v", path, err)
+			}
+			// An unusable answer only costs this chunk; otherwise stop sending
+			// further chunks as the backend is likely unavailable
+			if !ai.IsValidationError(err) {
+				aiErr = err
+			}
+			unverified = append(unverified, chunkMatches...)
+			continue
+		}
+
+		cov.ChunksAnalyzed++
+		cov.TokensUsed += tokens
+		cov.CandidatesSeen += len(chunkMatches)
+
+		// Uncertain small model answers are decided by the large model
+		if router.Escalate(req.Model, found) && cov.TokensUsed+tokens <= s.cfg.AIMaxTokens {
+			req.Model, req.Escalated = router.LargeModel, true
+			larger, err := s.aiClient.AnalyzeChunk(s.ctx, req)
+			if err == nil {
+				found = larger
+				cov.TokensUsed += tokens
+			} else if s.cfg.Verbose {
+				log.Printf("[AI-FULL] %s: escalation to %s failed, keeping the %s answer: %v", path, router.LargeModel, r.group.Model, err)
+			}
+		}
+
+		var chunkFindings []reconciled
+		for _, f := range found {
+			if pseudo != nil {
+				// Map placeholders back before locating the value
+				f.Value = pseudo.restore(f.Value)
+				f.Reason = pseudo.restore(f.Reason)
+			}
+			chunkFindings = append(chunkFindings, reconcile(chunk, f, chunkMatches))
+		}
+		aiFindings = append(aiFindings, chunkFindings...)
+
+		// A validated value the model waved through may have been hidden by
+		// instructions in the document: keep it and flag the file
+		if dismissed := dismissedMatches(chunkMatches, chunkFindings); len(dismissed) > 0 {
+			suspicious = append(suspicious, dismissed...)
+			addReason(&res.Injection, fmt.Sprintf("AI dismissed validated matches: %s", describeDismissed(dismissed)))
+			for _, reason := range detectInjectionHints(chunk.Text) {
+				addReason(&res.Injection, reason)
+			}
 		}
 	}
 
-	aiFindings, err := s.aiClient.AnalyzeFile(fullContext, typeList)
+	// Stop aborted the analysis: the file is dropped rather than stored and
+	// checkpointed half analysed, so -resume scans it again
+	if aiErr != nil && s.ctx.Err() != nil {
+		res.Error = s.ctx.Err()
+		res.ErrorMsg = fmt.Sprintf("AI analysis aborted: %v", res.Error)
+		return
+	}
+
+	switch {
+	case cov.ChunksAnalyzed > 0:
+		res.AIStatus = models.AIStatusAnalyzed
+	case errors.Is(aiErr, ai.ErrCircuitOpen):
+		res.AIStatus = models.AIStatusRegexOnly
+	case aiErr != nil:
+		res.AIStatus = models.AIStatusFailed
+	}
 
-	if err == nil {
-		for _, f := range aiFindings {
+	if s.cfg.Verbose && !cov.Complete() {
+		log.Printf("[AI] %s: %d of %d candidates reviewed (%d/%d chunks)", path, cov.CandidatesSeen, cov.Candidates, cov.ChunksAnalyzed, cov.Chunks)
+	}
+
+	for _, f := range mergeFindings(aiFindings) {
+		if s.cfg.Verbose {
+			log.Printf("[AI-FULL] %s: Found %s - %s", path, f.Type, f.Reason)
+		}
+
+		if s.Whitelist.Contains(f.Value) {
 			if s.cfg.Verbose {
-				log.Printf("[AI-FULL] %s: Found %s - %s", path, f.Type, f.Reason)
+				log.Printf("[WHITELIST] skipping known value: %s", f.Value)
 			}
+			continue
+		}
+
+		finding := models.Finding{
+			Type:       f.Type,
+			Snippet:    f.Value,
+			Confidence: f.Confidence, // AI-provided confidence
+			Offset:     f.Offset,
+			Origin:     models.OriginAI,
+			Context:    f.Reason, // Store the AI's explanation here
+		}
 
-			if s.Whitelist.Contains(f.Value) {
+		if !f.Found {
+			if s.cfg.AIUnverifiable == "drop" {
 				if s.cfg.Verbose {
-					log.Printf("[WHITELIST] skipping known value: %s", f.Value)
+					log.Printf("[AI-FULL] %s: dropping %s value not found in the file", path, f.Type)
 				}
 				continue
 			}
+			finding.Unverified = true
+		}
+		for _, m := range f.Sources {
+			finding.Origin = models.OriginConfirmed
+			if string(m.Type) == f.Type && finding.Subtype == "" {
+				finding.Subtype = m.Subtype
+			}
+		}
+
+		res.Findings = append(res.Findings, finding)
+	}
+
+	for _, m := range suspicious {
+		f := findingFromMatch(m)
+		f.Context = "Validated match dismissed by the AI (possible prompt injection)"
+		res.Findings = append(res.Findings, f)
+	}
 
-			res.Findings = append(res.Findings, models.Finding{
-				Type:       f.Type,
-				Snippet:    f.Value,
-				Confidence: f.Confidence, // AI-provided confidence
-				Offset:     0,
-				Context:    f.Reason, // Store the AI's explanation here
-			})
+	// Fallback: candidates the AI did not see are kept as raw regex matches so we don't lose them
+	for _, m := range unverified {
+		res.Findings = append(res.Findings, findingFromMatch(m))
+	}
+}
+
+func addReason(reasons *[]string, reason string) {
+	if !containsString(*reasons, reason) {
+		*reasons = append(*reasons, reason)
+	}
+}
+
+// chunkRoute is the part of a chunk's candidates reviewed by one model
+type chunkRoute struct {
+	index int // Chunk index
+	group ai.RouteGroup
+}
+
+// assignToChunks groups matches by the first chunk that contains them whole.
+// Matches outside all chunks are returned separately.
+func assignToChunks(chunks []ai.Chunk, matches []models.Match) ([][]models.Match, []models.Match) {
+	perChunk := make([][]models.Match, len(chunks))
+	var outside []models.Match
+	for _, m := range matches {
+		start := int(m.Offset)
+		end := start + len(m.Value)
+		placed := false
+		for i, c := range chunks {
+			if start >= c.Start && end <= c.End {
+				perChunk[i] = append(perChunk[i], m)
+				placed = true
+				break
+			}
 		}
-	} else {
-		if s.cfg.Verbose {
-			log.Printf("[AI-FULL] Error analyzing file %s: %v", path, err)
+		if !placed {
+			outside = append(outside, m)
+		}
+	}
+	return perChunk, outside
+}
+
+// buildChunkRequest renders the prompt input for one chunk
+// With a pseudonymizer the detected values are replaced by placeholders in
+// both the text and the candidate list, and the file name is left out.
+func buildChunkRequest(path string, index, total int, chunk ai.Chunk, matches []models.Match, pseudo *pseudonymizer) ai.ChunkRequest {
+	text := chunk.Text
+	name := filepath.Base(path)
+	if pseudo != nil {
+		text, matches = pseudo.apply(text, matches)
+		name = "document"
+	}
+
+	var sb strings.Builder
+	uniqueTypes := make(map[models.FindingType]bool)
+	var typeList []models.FindingType
+	for i, m := range matches {
+		sb.WriteString(describeMatch(i, m))
+		if !uniqueTypes[m.Type] {
+			uniqueTypes[m.Type] = true
+			typeList = append(typeList, m.Type)
+		}
+	}
+
+	return ai.ChunkRequest{
+		Label:         fmt.Sprintf("%s, part %d of %d", name, index+1, total),
+		Text:          text,
+		Candidates:    sb.String(),
+		Types:         typeList,
+		Pseudonymized: pseudo != nil,
+		File:          path,
+	}
+}
+
+// mergeFindings combines the per-chunk answers into one file verdict.
+// Overlapping chunks report the same value twice; the most confident answer wins.
+func mergeFindings(findings []reconciled) []reconciled {
+	index := make(map[string]int)
+	var merged []reconciled
+	for _, f := range findings {
+		key := fmt.Sprintf("%s\x00%d", strings.ToLower(f.Type), f.Offset)
+		if !f.Found {
+			key = strings.ToLower(f.Type) + "\x00" + compactValue(f.Value)
 		}
-		// Fallback: If AI fails, add the raw regex matches with lower confidence so we don't lose them
-		for _, m := range matches {
-			res.Findings = append(res.Findings, models.Finding{
-				Type:       string(m.Type),
-				Snippet:    m.Snippet,
-				Confidence: 0.5, // Lower confidence because AI didn't verify
-				Offset:     m.Offset,
-			})
+		if i, ok := index[key]; ok {
+			if f.Confidence > merged[i].Confidence {
+				merged[i] = f
+			}
+			continue
 		}
+		index[key] = len(merged)
+		merged = append(merged, f)
+	}
+	return merged
+}
+
+// findingFromMatch reports a match that was not verified by the AI
+func findingFromMatch(m models.Match) models.Finding {
+	return models.Finding{
+		Type:       string(m.Type),
+		Subtype:    m.Subtype,
+		Snippet:    m.Snippet,
+		Excerpt:    m.Snippet,
+		Confidence: regexConfidence(m),
+		Offset:     m.Offset,
+		Origin:     models.OriginRegex,
+	}
+}
+
+// splitCredentials separates credential matches from personal data matches
+func splitCredentials(matches []models.Match) (personal, credentials []models.Match) {
+	for _, m := range matches {
+		if m.Type == models.TypeCredential {
+			credentials = append(credentials, m)
+		} else {
+			personal = append(personal, m)
+		}
+	}
+	return personal, credentials
+}
+
+// regexConfidence is the confidence of an unverified match: the detector's own
+// score if it has one, 0.5 otherwise
+func regexConfidence(m models.Match) float64 {
+	if m.Confidence > 0 {
+		return m.Confidence
+	}
+	return 0.5
+}
+
+// describeMatch renders one candidate line of the AI prompt. The chunk text
+// already carries the surrounding context, so only the value is listed.
+func describeMatch(i int, m models.Match) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("MATCH[%d]: Type=%s", i, m.Type))
+	if m.Subtype != "" {
+		sb.WriteString(fmt.Sprintf(" Subtype=%s", m.Subtype))
+	}
+	if len(m.Labels) > 0 {
+		sb.WriteString(fmt.Sprintf(" Labels=%s", strings.Join(m.Labels, ",")))
+	}
+	if m.Confidence > 0 {
+		sb.WriteString(fmt.Sprintf(" Score=%.2f", m.Confidence))
 	}
+	sb.WriteString(fmt.Sprintf(" Value='%s' Offset=%d\n", m.Value, m.Offset))
+	return sb.String()
 }
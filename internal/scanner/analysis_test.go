@@ -0,0 +1,213 @@
+package scanner
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/ai"
+	"github.com/digimosa/ai-gdpr-scan/internal/config"
+	"github.com/digimosa/ai-gdpr-scan/internal/models"
+)
+
+// newTestScanner returns a scanner whose AI answers come from respond
+func newTestScanner(t *testing.T, respond func(ai.ChatRequest) (string, error)) (*Scanner, *ai.FakeProvider, *config.Config) {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	cfg.AIModel = "fake"
+	cfg.AIAuditLog = ""
+	cfg.AICacheTTL = 0
+	cfg.AILargeTypes = nil
+	cfg.WhitelistPath = filepath.Join(t.TempDir(), "whitelist.txt")
+
+	fake := &ai.FakeProvider{Respond: respond}
+	return NewScannerWithAI(cfg, ai.NewClientWithProvider(cfg, fake)), fake, cfg
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func answer(t *testing.T, findings ...ai.FindingResult) string {
+	t.Helper()
+	if findings == nil {
+		findings = []ai.FindingResult{}
+	}
+	data, err := json.Marshal(map[string]interface{}{"findings": findings})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func findingsOf(res models.ScanResult, findingType string) []models.Finding {
+	var out []models.Finding
+	for _, f := range res.Findings {
+		if f.Type == findingType {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func TestScanFileReconcilesOffsets(t *testing.T) {
+	text := "Kundin: Erika Musterfrau\nIBAN: DE89370400440532013000\n"
+	path := writeFile(t, "letter.txt", text)
+
+	s, fake, _ := newTestScanner(t, func(ai.ChatRequest) (string, error) {
+		return answer(t,
+			// Reformatted by the model
+			ai.FindingResult{Type: "IBAN", Value: "DE89 3704 0044 0532 0130 00", Reason: "labelled", Confidence: 0.95},
+			ai.FindingResult{Type: "Name", Value: "Erika Musterfrau", Reason: "customer", Confidence: 0.9},
+			// Not in the document
+			ai.FindingResult{Type: "Email", Value: "erika@example.com", Reason: "guessed", Confidence: 0.6},
+		), nil
+	})
+
+	res := s.ScanFileStages(path).Result
+	if res.Error != nil {
+		t.Fatal(res.Error)
+	}
+	if len(fake.Calls()) != 1 {
+		t.Fatalf("%d AI requests, want 1", len(fake.Calls()))
+	}
+	if res.AIStatus != models.AIStatusAnalyzed {
+		t.Errorf("AI status = %q", res.AIStatus)
+	}
+
+	iban := findingsOf(res, "IBAN")
+	if len(iban) != 1 {
+		t.Fatalf("IBAN findings = %+v", iban)
+	}
+	if want := int64(strings.Index(text, "DE89")); iban[0].Offset != want || iban[0].Unverified {
+		t.Errorf("IBAN at %d (unverified %v), want %d", iban[0].Offset, iban[0].Unverified, want)
+	}
+	if iban[0].Origin != models.OriginConfirmed {
+		t.Errorf("IBAN origin = %q, want %q", iban[0].Origin, models.OriginConfirmed)
+	}
+	if iban[0].Line != 2 {
+		t.Errorf("IBAN line = %d, want 2", iban[0].Line)
+	}
+
+	name := findingsOf(res, "Name")
+	if len(name) != 1 || name[0].Offset != int64(strings.Index(text, "Erika")) || name[0].Unverified {
+		t.Errorf("Name findings = %+v", name)
+	}
+	// The false-positive filter compares findings with their context
+	if len(name) == 1 && !strings.HasPrefix(name[0].Excerpt, "Kundin: Erika Musterfrau\nIBAN") {
+		t.Errorf("Name excerpt = %q", name[0].Excerpt)
+	}
+
+	email := findingsOf(res, "Email")
+	if len(email) != 1 || !email[0].Unverified {
+		t.Errorf("Email findings = %+v, want one unverified", email)
+	}
+}
+
+func TestScanFileMergesChunks(t *testing.T) {
+	var sb strings.Builder
+	for i := 0; i < 12; i++ {
+		if i == 4 {
+			sb.WriteString("Ansprechpartnerin ist Erika Musterfrau.\n")
+		}
+		sb.WriteString("Kontakt " + string(rune('a'+i)) + ": team" + string(rune('a'+i)) + "@example.com\n")
+	}
+	text := sb.String()
+	path := writeFile(t, "contacts.txt", text)
+
+	// The model reports the name in every chunk it sees, less sure each time
+	var mu sync.Mutex
+	seen := 0
+	s, fake, cfg := newTestScanner(t, func(req ai.ChatRequest) (string, error) {
+		confirmed, err := ai.ConfirmCandidates(req)
+		if err != nil || !strings.Contains(req.Messages[0].Content, "Erika Musterfrau") {
+			return confirmed, err
+		}
+		var resp struct {
+			Findings []ai.FindingResult `json:"findings"`
+		}
+		json.Unmarshal([]byte(confirmed), &resp)
+
+		mu.Lock()
+		seen++
+		confidence := 1 - 0.1*float64(seen)
+		mu.Unlock()
+		return answer(t, append(resp.Findings, ai.FindingResult{Type: "Name", Value: "Erika Musterfrau", Reason: "contact", Confidence: confidence})...), nil
+	})
+	cfg.AIChunkTokens, cfg.AIChunkOverlap = 40, 15
+
+	// The name must sit in the overlap of two chunks for the test to mean anything
+	inChunks := 0
+	for _, c := range ai.SplitChunks(text, cfg.AIChunkTokens, cfg.AIChunkOverlap) {
+		if strings.Contains(c.Text, "Erika Musterfrau") {
+			inChunks++
+		}
+	}
+	if inChunks < 2 {
+		t.Fatalf("the name is in %d chunks, want at least 2", inChunks)
+	}
+
+	res := s.ScanFileStages(path).Result
+	if res.Error != nil {
+		t.Fatal(res.Error)
+	}
+	if res.Coverage == nil || res.Coverage.Chunks < 2 || !res.Coverage.Complete() {
+		t.Fatalf("coverage = %+v", res.Coverage)
+	}
+	if seen < 2 {
+		t.Fatalf("the name was reported by %d chunks, want at least 2", seen)
+	}
+
+	name := findingsOf(res, "Name")
+	if len(name) != 1 {
+		t.Fatalf("Name findings = %+v, want one merged finding", name)
+	}
+	if name[0].Offset != int64(strings.Index(text, "Erika")) || name[0].Confidence != 0.9 {
+		t.Errorf("Name finding at %d with confidence %v, want %d with the most confident answer 0.9",
+			name[0].Offset, name[0].Confidence, strings.Index(text, "Erika"))
+	}
+
+	// Every address is reported once, although some appear in two chunks
+	emails := findingsOf(res, "Email")
+	if len(emails) != 12 {
+		t.Errorf("%d Email findings, want 12", len(emails))
+	}
+	offsets := make(map[int64]bool)
+	for _, f := range emails {
+		if offsets[f.Offset] {
+			t.Errorf("Email at %d reported twice", f.Offset)
+		}
+		offsets[f.Offset] = true
+	}
+	if len(res.Injection) > 0 {
+		t.Errorf("injection flagged: %v", res.Injection)
+	}
+	if len(fake.Calls()) != res.Coverage.Chunks {
+		t.Errorf("%d AI requests for %d chunks", len(fake.Calls()), res.Coverage.Chunks)
+	}
+}
+
+func TestScanFileAIUnavailable(t *testing.T) {
+	path := writeFile(t, "letter.txt", "IBAN: DE89370400440532013000\n")
+	s, _, _ := newTestScanner(t, func(ai.ChatRequest) (string, error) {
+		return "", &ai.HTTPError{StatusCode: 400, Body: "bad request"}
+	})
+
+	res := s.ScanFileStages(path).Result
+	if res.AIStatus != models.AIStatusFailed {
+		t.Errorf("AI status = %q, want %q", res.AIStatus, models.AIStatusFailed)
+	}
+	// Detector matches are kept when the model cannot review them
+	iban := findingsOf(res, "IBAN")
+	if len(iban) != 1 || iban[0].Origin != models.OriginRegex {
+		t.Errorf("IBAN findings = %+v", iban)
+	}
+}
@@ -0,0 +1,74 @@
+package scanner
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/ai"
+	"github.com/digimosa/ai-gdpr-scan/internal/extractor"
+	"github.com/digimosa/ai-gdpr-scan/internal/models"
+)
+
+// Sources of a document category
+const (
+	categoryRules   = "rules"
+	categoryAI      = "ai"
+	categoryRulesAI = "rules+ai"
+)
+
+// classifyDocument assigns res a document category from the keyword profiles
+// and detector signals. With AIClassify, files with findings the profiles
+// cannot classify confidently are also shown to the model.
+func (s *Scanner) classifyDocument(path string, doc *extractor.Document, matches []models.Match, res *models.ScanResult) {
+	rules := s.classifier.Classify(doc.Text, matches)
+	if rules.Category != "" {
+		res.Category, res.CategoryConfidence, res.CategorySource = rules.Category, rules.Confidence, categoryRules
+	}
+
+	if !s.cfg.AIClassify || s.cfg.DisableAI || s.aiClient == nil || !s.aiClient.Available() ||
+		len(res.Findings) == 0 || rules.Confidence >= s.cfg.AIClassifyBelow {
+		return
+	}
+
+	chunks := ai.SplitChunks(doc.Text, s.cfg.AIClassifyTokens, 0)
+	if len(chunks) == 0 {
+		return
+	}
+
+	// Credentials never reach the model; other values only when pseudonymizing
+	personal, credentials := splitCredentials(matches)
+	masked := credentials
+	label := filepath.Base(path)
+	if s.cfg.AIPseudonymize {
+		masked = append(masked, personal...)
+		label = "document"
+	}
+	excerpt, _ := newPseudonymizer().apply(chunks[0].Text, masked)
+
+	verdict, err := s.aiClient.ClassifyDocument(s.ctx, path, fmt.Sprintf("%s, start", label), excerpt, len(masked) > 0)
+	if err != nil {
+		if s.cfg.Verbose {
+			log.Printf("[AI] %s: classification failed: %v", path, err)
+		}
+		return
+	}
+	mergeCategory(res, verdict)
+}
+
+// mergeCategory combines the profile category in res with the model's
+// verdict: agreement raises the confidence, otherwise the more confident
+// verdict wins
+func mergeCategory(res *models.ScanResult, verdict *ai.Classification) {
+	switch {
+	case verdict.Category == res.Category:
+		res.CategoryConfidence = 1 - (1-res.CategoryConfidence)*(1-verdict.Confidence)
+		res.CategorySource = categoryRulesAI
+	case verdict.Confidence <= res.CategoryConfidence:
+		// Keep the profile category
+	case verdict.Category == ai.CategoryOther:
+		res.Category, res.CategoryConfidence, res.CategorySource = "", 0, ""
+	default:
+		res.Category, res.CategoryConfidence, res.CategorySource = verdict.Category, verdict.Confidence, categoryAI
+	}
+}
@@ -0,0 +1,95 @@
+package scanner
+
+import (
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/ai"
+	"github.com/digimosa/ai-gdpr-scan/internal/config"
+	"github.com/digimosa/ai-gdpr-scan/internal/extractor"
+	"github.com/digimosa/ai-gdpr-scan/internal/extractor/detectors"
+	"github.com/digimosa/ai-gdpr-scan/internal/models"
+	"github.com/digimosa/ai-gdpr-scan/internal/storage"
+)
+
+// maxFeedbackExamples bounds how many labelled findings are read per scan
+const maxFeedbackExamples = 500
+
+var (
+	digitPattern    = regexp.MustCompile(`[0-9]`)
+	typePlaceholder = regexp.MustCompile(`<[A-Z]+>`)
+)
+
+// loadExamples builds the few-shot examples from reviewer feedback
+func loadExamples(cfg *config.Config) *ai.ExampleSet {
+	labelled, err := storage.LabelledFindings(maxFeedbackExamples)
+	if err != nil {
+		log.Printf("[AI] could not load feedback examples: %v", err)
+		return nil
+	}
+
+	dets := extractor.NewDetectors(cfg)
+	var examples []ai.Example
+	for _, f := range labelled {
+		if f.Type == string(models.TypeCredential) {
+			continue
+		}
+		correct := f.Feedback == "Correct"
+		text := anonymizeExample(f.Value, correct, dets)
+		if text == "" {
+			continue
+		}
+		examples = append(examples, ai.Example{
+			Type:    models.FindingType(f.Type),
+			Subtype: f.Subtype,
+			Text:    text,
+			Correct: correct,
+		})
+	}
+
+	set := ai.NewExampleSet(examples, cfg.AIFewShotPerType, cfg.AIFewShotTokens)
+	if cfg.Verbose {
+		log.Printf("[AI] loaded %d feedback examples", set.Len())
+	}
+	return set
+}
+
+// anonymizeExample replaces detected values in a reviewed snippet with
+// <TYPE> placeholders and masks all digits. A confirmed finding must contain
+// a detectable value besides its context; snippets that are nothing but the
+// value would only show a placeholder and are skipped. Words in false
+// positives are kept, since reviewers judged them not to be personal data.
+func anonymizeExample(snippet string, correct bool, dets []detectors.Detector) string {
+	var matches []models.Match
+	for _, d := range dets {
+		for _, m := range d.Detect(snippet) {
+			if !m.Keyword && m.Value != "" {
+				matches = append(matches, m)
+			}
+		}
+	}
+	if correct && len(matches) == 0 {
+		return ""
+	}
+
+	// Replace from the end so earlier offsets stay valid; skip overlaps
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Offset > matches[j].Offset })
+	text := snippet
+	limit := int64(len(snippet))
+	for _, m := range matches {
+		end := m.Offset + int64(len(m.Value))
+		if end > limit {
+			continue
+		}
+		text = text[:m.Offset] + "<" + strings.ToUpper(string(m.Type)) + ">" + text[end:]
+		limit = m.Offset
+	}
+
+	text = strings.Join(strings.Fields(digitPattern.ReplaceAllString(text, "0")), " ")
+	if correct && strings.Trim(typePlaceholder.ReplaceAllString(text, ""), " .,;:-") == "" {
+		return "" // Only placeholders left
+	}
+	return text
+}
@@ -0,0 +1,89 @@
+package scanner
+
+import (
+	"context"
+	"log"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/ai"
+	"github.com/digimosa/ai-gdpr-scan/internal/config"
+	"github.com/digimosa/ai-gdpr-scan/internal/models"
+	"github.com/digimosa/ai-gdpr-scan/internal/storage"
+)
+
+// loadFPFilter embeds the reviewed findings as references of the
+// false-positive filter. The filter compares detected values in their
+// context, so it stays off when values must not leave the machine, and when
+// embedding fails.
+func loadFPFilter(cfg *config.Config, aiClient *ai.Client) *ai.FPFilter {
+	if cfg.AIPseudonymize {
+		log.Printf("[AI] false-positive filter disabled: it embeds detected values, which pseudonymization keeps local")
+		return nil
+	}
+	labelled, err := storage.LabelledFindings(maxFeedbackExamples)
+	if err != nil {
+		log.Printf("[AI] could not load reviewed findings for the false-positive filter: %v", err)
+		return nil
+	}
+
+	// Candidates are embedded with their snippet, so references need the
+	// same context. Detector findings store it as their value; AI findings
+	// stored before excerpts were recorded only have the bare value.
+	var examples []ai.Example
+	for _, f := range labelled {
+		if f.Type == string(models.TypeCredential) {
+			continue
+		}
+		text := f.Excerpt
+		if text == "" && f.Origin == models.OriginRegex {
+			text = f.Value
+		}
+		if text == "" {
+			continue
+		}
+		examples = append(examples, ai.Example{
+			Type:    models.FindingType(f.Type),
+			Subtype: f.Subtype,
+			Text:    text,
+			Correct: f.Feedback == "Correct",
+		})
+	}
+
+	if aiClient.Embeddings == nil {
+		aiClient.Embeddings = storage.NewEmbeddingCache()
+	}
+	filter, err := aiClient.NewFPFilter(context.Background(), cfg, examples)
+	if err != nil {
+		log.Printf("[AI] false-positive filter disabled: %v", err)
+		return nil
+	}
+	if cfg.Verbose {
+		log.Printf("[AI] false-positive filter compares with %d reviewed findings", filter.References())
+	}
+	return filter
+}
+
+// filterFalsePositives drops the candidates whose snippets resemble reviewed
+// false positives. Without a filter, or when embedding fails, all are kept.
+func (s *Scanner) filterFalsePositives(path string, matches []models.Match) []models.Match {
+	if s.cfg.DisableAI || s.aiClient == nil || s.aiClient.Filter == nil || len(matches) == 0 {
+		return matches
+	}
+
+	votes, err := s.aiClient.FilterCandidates(s.ctx, matches)
+	if err != nil {
+		log.Printf("[AI] false-positive filter failed for %s: %v", path, err)
+		return matches
+	}
+
+	kept := make([]models.Match, 0, len(matches))
+	for i, m := range matches {
+		if v := votes[i]; v != nil && v.Drop {
+			if s.cfg.Verbose {
+				log.Printf("[FILTER] %s: dropped %s candidate at offset %d (%d of %d similar reviewed findings were incorrect)", path, m.Type, m.Offset, v.Incorrect, v.Neighbours)
+			}
+			continue
+		}
+		kept = append(kept, m)
+	}
+	return kept
+}
@@ -0,0 +1,103 @@
+package scanner
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/fingerprint"
+	"github.com/digimosa/ai-gdpr-scan/internal/models"
+	"github.com/digimosa/ai-gdpr-scan/internal/scoring"
+	"github.com/digimosa/ai-gdpr-scan/internal/storage"
+)
+
+// checkInventory compares a file with its inventory entry and records the
+// current fingerprint on res, so every stored scan keeps the inventory up to
+// date. The content is only hashed when size, modification time or inode
+// differ. Incremental scans also record the change, and for an unchanged file
+// carry the result stored by its last scan forward and return true.
+func (s *Scanner) checkInventory(path string, info os.FileInfo, res *models.ScanResult) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	fp := fingerprint.Of(info)
+	res.Fingerprint = &fp
+	if s.cfg.Incremental {
+		res.Change = models.ChangeNew
+	}
+
+	entry, found := storage.GetInventory(abs)
+	if found && entry.Removed {
+		found = false
+	}
+	unchanged := false
+	if found {
+		if s.cfg.Incremental {
+			res.Change = models.ChangeModified
+		}
+		if last := entry.Fingerprint(); fingerprint.SameMetadata(fp, last) {
+			fp.Hash, unchanged = last.Hash, true
+		}
+	}
+	if fp.Hash == "" {
+		hash, err := fingerprint.Hash(path)
+		if err != nil {
+			// Extraction reports the error
+			return false
+		}
+		fp.Hash = hash
+		// Touched or copied back without a content change
+		unchanged = found && entry.Size == fp.Size && entry.Hash == hash
+	}
+	if !unchanged || !s.cfg.Incremental {
+		return false
+	}
+
+	stored, err := storage.FileResult(entry.ScanID, entry.FilePath)
+	if err != nil {
+		log.Printf("[INCREMENTAL] could not load the last result of %s, scanning it again: %v", path, err)
+		return false
+	}
+	res.Change = models.ChangeUnchanged
+	res.Findings = stored.Findings
+	res.Category = stored.Category
+	res.CategoryConfidence = stored.CategoryConfidence
+	res.CategorySource = stored.CategorySource
+	res.RiskScore = scoring.RiskScore(res.Findings)
+	if s.cfg.Verbose {
+		log.Printf("[INCREMENTAL] %s unchanged since scan %d, %d findings carried forward", path, entry.ScanID, len(res.Findings))
+	}
+	return true
+}
+
+// updateInventory records the version of a file whose result was stored
+func (s *Scanner) updateInventory(res models.ScanResult) {
+	if res.Fingerprint == nil || res.Error != nil {
+		return
+	}
+	abs, err := filepath.Abs(res.FilePath)
+	if err != nil {
+		return
+	}
+	if err := storage.UpdateInventory(abs, res.FilePath, *res.Fingerprint, s.ScanModelID); err != nil {
+		log.Printf("[ERROR] could not update the file inventory for %s: %v", res.FilePath, err)
+	}
+}
+
+// markRemoved flags inventory entries of deleted files below the scanned root.
+// Only incremental scans report them.
+func (s *Scanner) markRemoved() {
+	root, err := filepath.Abs(s.cfg.RootPath)
+	if err != nil {
+		return
+	}
+	removed, err := storage.MarkRemoved(filepath.Clean(root), s.ScanModelID)
+	if err != nil {
+		log.Printf("[ERROR] could not mark removed files in the inventory: %v", err)
+		return
+	}
+	if s.cfg.Incremental {
+		s.Report.SetRemoved(removed)
+	}
+}
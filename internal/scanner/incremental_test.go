@@ -0,0 +1,60 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/ai"
+	"github.com/digimosa/ai-gdpr-scan/internal/storage"
+)
+
+func TestFullScanFillsInventory(t *testing.T) {
+	if err := storage.Init(filepath.Join(t.TempDir(), "scans.db")); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { storage.DB = nil })
+
+	root := t.TempDir()
+	kept := filepath.Join(root, "kept.txt")
+	deleted := filepath.Join(root, "deleted.txt")
+	for _, path := range []string{kept, deleted} {
+		if err := os.WriteFile(path, []byte("IBAN: DE89370400440532013000\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scan := func(incremental bool) (*Scanner, *ai.FakeProvider) {
+		s, fake, cfg := newTestScanner(t, ai.ConfirmCandidates)
+		cfg.RootPath, cfg.Workers, cfg.Incremental = root, 2, incremental
+		s.Start()
+		s.Wait()
+		return s, fake
+	}
+
+	// A full scan records every file it stored
+	full, _ := scan(false)
+	if sum := full.Report.Summary; sum.NewFiles != 0 || sum.TotalFilesWithPII != 2 {
+		t.Fatalf("full scan summary = %+v", sum)
+	}
+	for _, path := range []string{kept, deleted} {
+		abs, _ := filepath.Abs(path)
+		if entry, ok := storage.GetInventory(abs); !ok || entry.Hash == "" || entry.ScanID != full.ScanModelID {
+			t.Errorf("inventory entry of %s = %+v", path, entry)
+		}
+	}
+
+	// The next incremental scan builds on it
+	os.Remove(deleted)
+	next, fake := scan(true)
+	sum := next.Report.Summary
+	if sum.UnchangedFiles != 1 || sum.NewFiles != 0 || sum.RemovedFiles != 1 {
+		t.Errorf("incremental scan: %d unchanged, %d new, %d removed; want 1, 0, 1", sum.UnchangedFiles, sum.NewFiles, sum.RemovedFiles)
+	}
+	if sum.TotalPIIFound != 1 {
+		t.Errorf("%d findings, want the one carried forward", sum.TotalPIIFound)
+	}
+	if n := len(fake.Calls()); n != 0 {
+		t.Errorf("%d AI requests for an unchanged file", n)
+	}
+}
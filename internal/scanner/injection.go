@@ -0,0 +1,88 @@
+package scanner
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/models"
+)
+
+// injectionHint is text that addresses the model rather than a human reader
+type injectionHint struct {
+	Pattern *regexp.Regexp
+	Reason  string
+}
+
+var injectionHints = []injectionHint{
+	{regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override)\b.{0,40}\b(previous|prior|above|earlier|all|any)\b.{0,30}\b(instructions?|prompts?|rules|guidelines)\b`), "instruction override phrase"},
+	{regexp.MustCompile(`(?i)\b(ignoriere|vergiss|missachte|überschreibe)\b.{0,50}\b(anweisungen|instruktionen|regeln|vorgaben)\b`), "instruction override phrase"},
+	{regexp.MustCompile(`(?i)\b(return|respond with|answer with|output)\b.{0,20}(\[\s*\]|empty (list|array|result)|no findings|"findings"\s*:\s*\[\s*\])`), "request for an empty result"},
+	{regexp.MustCompile(`(?i)\b(you are now|new instructions|system prompt|as an ai( language)? model|neue anweisung)\b`), "text addressed to a language model"},
+	{regexp.MustCompile(`"""|<<<|>>>|\x60\x60\x60`), "prompt delimiter sequence"},
+}
+
+// detectInjectionHints lists the kinds of model-directed text found in text
+func detectInjectionHints(text string) []string {
+	var reasons []string
+	for _, h := range injectionHints {
+		if h.Pattern.MatchString(text) && !containsString(reasons, h.Reason) {
+			reasons = append(reasons, h.Reason)
+		}
+	}
+	return reasons
+}
+
+// isStrongMatch reports matches that passed a checksum (IBAN MOD-97, card
+// Luhn), so an AI verdict against them is suspicious. Context scores are not
+// enough: models rightly dismiss e.g. example addresses next to keywords.
+func isStrongMatch(m models.Match) bool {
+	if m.Keyword {
+		return false
+	}
+	return m.Type == models.TypeIBAN || m.Type == models.TypeCreditCard
+}
+
+// dismissedMatches returns the strong matches no AI finding covers
+func dismissedMatches(matches []models.Match, findings []reconciled) []models.Match {
+	covered := make(map[int64]bool)
+	for _, f := range findings {
+		for _, m := range f.Sources {
+			covered[m.Offset] = true
+		}
+	}
+
+	var dismissed []models.Match
+	for _, m := range matches {
+		if isStrongMatch(m) && !covered[m.Offset] {
+			dismissed = append(dismissed, m)
+		}
+	}
+	return dismissed
+}
+
+// describeDismissed summarizes dismissed matches by type, e.g. "2 IBAN, 1 CreditCard"
+func describeDismissed(matches []models.Match) string {
+	counts := make(map[models.FindingType]int)
+	var order []models.FindingType
+	for _, m := range matches {
+		if counts[m.Type] == 0 {
+			order = append(order, m.Type)
+		}
+		counts[m.Type]++
+	}
+	parts := make([]string, len(order))
+	for i, t := range order {
+		parts[i] = fmt.Sprintf("%d %s", counts[t], t)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,7 @@ package scanner
 
 import (
 	"fmt"
+	"log"
 	"time"
 
 	"github.com/digimosa/ai-gdpr-scan/internal/storage"
@@ -15,20 +16,20 @@ func (s *Scanner) processResults() {
 	for res := range s.results {
 		count++
 
+		if res.Skipped != "" {
+			s.Report.AddSkipped(res.Skipped)
+			continue
+		}
+
 		// Add to report regardless of findings (tracks total files scanned)
 		s.Report.AddResult(res)
 
-		// Save PII to DB if Scan ID exists
-		if s.ScanModelID != 0 && len(res.Findings) > 0 {
-			for _, f := range res.Findings {
-				// We don't have file path in Finding struct directly, need to check how it's structured
-				// ScanResult has FilePath.
-				// f.Snippet is the value
-				// f.Context is the AI reason
-				if f.Confidence == 0 {
-					fmt.Printf("[DEBUG-ZERO-CONF] Saving %s finding for %s with 0 confidence! (Type: %s)\n", f.Type, res.FilePath, f.Type)
-				}
-				_ = storage.SaveFinding(s.ScanModelID, res.FilePath, f.Type, f.Snippet, f.Context, f.Confidence)
+		// Save PII to DB if Scan ID exists, checkpointing the file for -resume
+		if s.ScanModelID != 0 {
+			if err := storage.SaveResult(s.ScanModelID, res); err != nil {
+				log.Printf("[ERROR] could not save result of %s: %v", res.FilePath, err)
+			} else {
+				s.updateInventory(res)
 			}
 		}
 
@@ -37,9 +38,17 @@ func (s *Scanner) processResults() {
 			continue
 		}
 		if len(res.Findings) > 0 {
-			fmt.Printf("[FOUND] %s: %d potential PII matches\n", res.FilePath, len(res.Findings))
+			if res.Category != "" {
+				fmt.Printf("[FOUND] %s (%s): %d potential PII matches\n", res.FilePath, res.Category, len(res.Findings))
+			} else {
+				fmt.Printf("[FOUND] %s: %d potential PII matches\n", res.FilePath, len(res.Findings))
+			}
 			for _, f := range res.Findings {
-				fmt.Printf("  - %s (Confidence: %.2f)\n", f.Type, f.Confidence)
+				if f.Subtype != "" {
+					fmt.Printf("  - %s/%s (Confidence: %.2f)\n", f.Type, f.Subtype, f.Confidence)
+				} else {
+					fmt.Printf("  - %s (Confidence: %.2f)\n", f.Type, f.Confidence)
+				}
 			}
 		}
 
@@ -47,6 +56,14 @@ func (s *Scanner) processResults() {
 			fmt.Printf("Processed %d files... (Rate: %.2f files/sec)\n", count, float64(count)/time.Since(start).Seconds())
 		}
 	}
+	if s.aiClient != nil {
+		s.Report.SetAIStats(s.aiClient.Stats())
+	}
+	if s.Cancelled() {
+		s.Report.SetCancelled()
+	} else if s.ScanModelID != 0 {
+		s.markRemoved()
+	}
 	s.Report.Finalize() // Finalize timestamps
 
 	// Update Scan Completion Status in DB
@@ -54,7 +71,12 @@ func (s *Scanner) processResults() {
 		storage.GetScanByID(fmt.Sprintf("%d", s.ScanModelID)) // Reload? Or just update fields
 		// We need a helper to update by ID directly or retrieve first
 		if scan, err := storage.GetScanByID(fmt.Sprintf("%d", s.ScanModelID)); err == nil {
-			storage.CompleteScan(scan, s.Report.Summary.TotalFilesScanned, s.Report.Summary.TotalFilesWithPII, s.Report.Summary.TotalPIIFound)
+			summary := s.Report.Summary
+			if summary.Cancelled {
+				storage.CancelScan(scan, summary.TotalFilesScanned, summary.TotalFilesWithPII, summary.TotalPIIFound)
+			} else {
+				storage.CompleteScan(scan, summary.TotalFilesScanned, summary.TotalFilesWithPII, summary.TotalPIIFound)
+			}
 		}
 	}
 
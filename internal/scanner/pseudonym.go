@@ -0,0 +1,91 @@
+package scanner
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/models"
+)
+
+// minPseudonymLength skips values too short to replace safely across a chunk
+const minPseudonymLength = 3
+
+var placeholderPattern = regexp.MustCompile(`<[A-Z]+_\d+>`)
+
+// pseudonymizer replaces detected values with typed placeholders such as
+// <IBAN_1> before text is sent to the model, and maps placeholders in the
+// answer back locally. One instance covers one file, so a value keeps its
+// placeholder across chunks.
+type pseudonymizer struct {
+	placeholders map[string]string // type + value -> placeholder
+	originals    map[string]string // placeholder -> value
+	counters     map[models.FindingType]int
+}
+
+func newPseudonymizer() *pseudonymizer {
+	return &pseudonymizer{
+		placeholders: make(map[string]string),
+		originals:    make(map[string]string),
+		counters:     make(map[models.FindingType]int),
+	}
+}
+
+// placeholder returns the placeholder for a match value, assigning the next
+// number of its type on first use
+func (p *pseudonymizer) placeholder(m models.Match) string {
+	key := string(m.Type) + "\x00" + m.Value
+	if ph, ok := p.placeholders[key]; ok {
+		return ph
+	}
+	p.counters[m.Type]++
+	ph := fmt.Sprintf("<%s_%d>", strings.ToUpper(string(m.Type)), p.counters[m.Type])
+	p.placeholders[key] = ph
+	p.originals[ph] = m.Value
+	return ph
+}
+
+// apply replaces every occurrence of the matched values in text, longest
+// first so values containing other values are replaced whole. It returns the
+// masked text and the matches with their values swapped for placeholders.
+func (p *pseudonymizer) apply(text string, matches []models.Match) (string, []models.Match) {
+	masked := make([]models.Match, len(matches))
+	for i, m := range matches {
+		masked[i] = m
+		if m.Keyword || len(m.Value) < minPseudonymLength {
+			continue
+		}
+		masked[i].Value = p.placeholder(m)
+		masked[i].Snippet = ""
+	}
+
+	type replacement struct{ value, placeholder string }
+	var replacements []replacement
+	seen := make(map[string]bool)
+	for i, m := range matches {
+		if masked[i].Value != m.Value && !seen[m.Value] {
+			seen[m.Value] = true
+			replacements = append(replacements, replacement{m.Value, masked[i].Value})
+		}
+	}
+	sort.SliceStable(replacements, func(i, j int) bool {
+		return len(replacements[i].value) > len(replacements[j].value)
+	})
+
+	pairs := make([]string, 0, 2*len(replacements))
+	for _, r := range replacements {
+		pairs = append(pairs, r.value, r.placeholder)
+	}
+	return strings.NewReplacer(pairs...).Replace(text), masked
+}
+
+// restore replaces known placeholders in s with the original values
+func (p *pseudonymizer) restore(s string) string {
+	return placeholderPattern.ReplaceAllStringFunc(s, func(ph string) string {
+		if v, ok := p.originals[ph]; ok {
+			return v
+		}
+		return ph
+	})
+}
@@ -0,0 +1,99 @@
+package scanner
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/ai"
+	"github.com/digimosa/ai-gdpr-scan/internal/models"
+)
+
+// maxSearchValue bounds the values located with a whitespace tolerant pattern;
+// longer values are only searched verbatim
+const maxSearchValue = 512
+
+// reconciled is an AI finding located in the extracted text
+type reconciled struct {
+	ai.FindingResult
+	Offset  int64
+	Length  int
+	Found   bool           // The value occurs in the chunk it was reported for
+	Sources []models.Match // Detector matches covered by the value
+}
+
+// reconcile locates an AI finding in the chunk it was reported for and links
+// it to the detector matches it covers. Values the model reformatted (e.g. an
+// IBAN with added spaces) are matched against the candidates first.
+func reconcile(chunk ai.Chunk, f ai.FindingResult, matches []models.Match) reconciled {
+	r := reconciled{FindingResult: f}
+	value := strings.TrimSpace(f.Value)
+	if value == "" {
+		return r
+	}
+
+	key := compactValue(value)
+	for _, m := range matches {
+		if compactValue(m.Value) == key {
+			r.Offset, r.Length, r.Found = m.Offset, len(m.Value), true
+			break
+		}
+	}
+
+	if !r.Found {
+		var spans [][]int
+		if len(value) <= maxSearchValue {
+			spans = valuePattern(value).FindAllStringIndex(chunk.Text, -1)
+		} else if i := strings.Index(chunk.Text, value); i >= 0 {
+			spans = [][]int{{i, i + len(value)}}
+		}
+
+		// Prefer the occurrence a detector also flagged
+		for _, span := range spans {
+			start, end := int64(chunk.Start+span[0]), int64(chunk.Start+span[1])
+			flagged := len(overlapping(matches, start, end)) > 0
+			if flagged || !r.Found {
+				r.Offset, r.Length, r.Found = start, span[1]-span[0], true
+			}
+			if flagged {
+				break
+			}
+		}
+	}
+
+	if r.Found {
+		r.Sources = overlapping(matches, r.Offset, r.Offset+int64(r.Length))
+	}
+	return r
+}
+
+// valuePattern matches value case-insensitively with any run of whitespace
+// between its words, as models often rejoin values broken across lines
+func valuePattern(value string) *regexp.Regexp {
+	words := strings.Fields(value)
+	for i, w := range words {
+		words[i] = regexp.QuoteMeta(w)
+	}
+	return regexp.MustCompile(`(?i)` + strings.Join(words, `\s+`))
+}
+
+// compactValue normalizes a value for comparison: lower case without whitespace
+func compactValue(value string) string {
+	return strings.ToLower(strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, value))
+}
+
+// overlapping returns the matches that intersect [start, end)
+func overlapping(matches []models.Match, start, end int64) []models.Match {
+	var found []models.Match
+	for _, m := range matches {
+		if m.Offset < end && start < m.Offset+int64(len(m.Value)) {
+			found = append(found, m)
+		}
+	}
+	return found
+}
@@ -2,13 +2,18 @@ package scanner
 
 import (
 	"context"
+	"log"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/digimosa/ai-gdpr-scan/internal/ai"
+	"github.com/digimosa/ai-gdpr-scan/internal/classify"
 	"github.com/digimosa/ai-gdpr-scan/internal/config"
 	"github.com/digimosa/ai-gdpr-scan/internal/extractor"
 	"github.com/digimosa/ai-gdpr-scan/internal/models"
 	"github.com/digimosa/ai-gdpr-scan/internal/reporting"
+	"github.com/digimosa/ai-gdpr-scan/internal/scoring"
 	"github.com/digimosa/ai-gdpr-scan/internal/storage"
 	"github.com/digimosa/ai-gdpr-scan/internal/whitelist"
 )
@@ -19,18 +24,47 @@ type Scanner struct {
 	jobs           chan models.Job
 	results        chan models.ScanResult
 	wg             sync.WaitGroup
-	ctx            context.Context
+	ctx            context.Context // Aborts extraction and AI requests
 	cancel         context.CancelFunc
+	walkCtx        context.Context // Stops the walker and the queued jobs
+	stopWalk       context.CancelFunc
+	cancelled      atomic.Bool
 	done           chan struct{}
-	aiClient       *ai.OllamaClient
+	aiClient       *ai.Client // nil when no AI backend is configured
 	Report         *reporting.Report
 	scannerFactory *extractor.Factory
+	scorer         *scoring.Scorer
+	classifier     *classify.Classifier
 	Whitelist      *whitelist.Whitelist
-	ScanModelID    uint // ID of the current scan in DB
+	ScanModelID    uint            // ID of the current scan in DB
+	completed      map[string]bool // Files a resumed scan already stored
 }
 
+// NewScanner creates a scanner using the AI backend selected in cfg
 func NewScanner(cfg *config.Config) *Scanner {
+	aiClient, err := ai.NewClient(cfg)
+	if err != nil {
+		log.Printf("[AI] %v - continuing with regex-only analysis", err)
+		aiClient = nil
+	}
+	return NewScannerWithAI(cfg, aiClient)
+}
+
+// NewScannerWithAI creates a scanner using the given AI client, e.g. one
+// backed by an ai.FakeProvider. A nil client means regex-only analysis.
+func NewScannerWithAI(cfg *config.Config, aiClient *ai.Client) *Scanner {
+	if aiClient != nil && aiClient.Cache == nil && cfg.AICacheTTL > 0 && storage.DB != nil {
+		aiClient.Cache = storage.NewVerdictCache(cfg.AICacheTTL)
+	}
+	if aiClient != nil && aiClient.Examples == nil && cfg.AIFewShotPerType > 0 && storage.DB != nil {
+		aiClient.Examples = loadExamples(cfg)
+	}
+	if aiClient != nil && aiClient.Filter == nil && cfg.AIEmbedFilter && !cfg.DisableAI && storage.DB != nil {
+		aiClient.Filter = loadFPFilter(cfg, aiClient)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
+	walkCtx, stopWalk := context.WithCancel(ctx)
 
 	wl, err := whitelist.NewWhitelist(cfg.WhitelistPath)
 	if err != nil {
@@ -46,10 +80,14 @@ func NewScanner(cfg *config.Config) *Scanner {
 		results:        make(chan models.ScanResult, cfg.Workers*4),
 		ctx:            ctx,
 		cancel:         cancel,
+		walkCtx:        walkCtx,
+		stopWalk:       stopWalk,
 		done:           make(chan struct{}),
-		aiClient:       ai.NewClient(cfg),
+		aiClient:       aiClient,
 		Report:         reporting.NewReport(),
-		scannerFactory: extractor.NewFactory(),
+		scannerFactory: extractor.NewFactory(cfg),
+		scorer:         scoring.NewScorer(cfg),
+		classifier:     classify.NewClassifier(),
 		Whitelist:      wl,
 	}
 	s.Report.Summary.RootPath = cfg.RootPath
@@ -58,13 +96,15 @@ func NewScanner(cfg *config.Config) *Scanner {
 
 // Start initializes the worker pool and starts the scan
 func (s *Scanner) Start() {
-	// Create Scan Record
-	scanModel, err := storage.CreateScan(s.cfg.RootPath)
-	if err == nil {
-		s.ScanModelID = scanModel.ID
-	} else {
-		// Log error but proceed?
-		// log.Printf("Failed to create scan record: %v", err)
+	// Create Scan Record, unless an interrupted one is resumed
+	if s.ScanModelID == 0 {
+		scanModel, err := storage.CreateScan(s.cfg.RootPath)
+		if err == nil {
+			s.ScanModelID = scanModel.ID
+		} else {
+			// Log error but proceed?
+			// log.Printf("Failed to create scan record: %v", err)
+		}
 	}
 
 	// Start workers
@@ -80,9 +120,57 @@ func (s *Scanner) Start() {
 	go s.walkFiles()
 }
 
+// Resume continues an interrupted scan, see storage.ResumeScan, instead of
+// starting a new one. The scan's root path replaces cfg.RootPath, files it
+// already stored are skipped and the report starts from their results.
+// Call it before Start.
+func (s *Scanner) Resume(scan *storage.ScanModel) error {
+	done, err := storage.CheckpointedFiles(scan)
+	if err != nil {
+		return err
+	}
+	s.ScanModelID = scan.ID
+	s.completed = done
+	s.cfg.RootPath = scan.RootPath
+	s.Report.Summary.RootPath = scan.RootPath
+	s.Report.Summary.StartTime = scan.StartTime
+
+	seen := make(map[string]bool)
+	for _, res := range scan.Results() {
+		seen[res.FilePath] = true
+		s.Report.AddResult(res)
+	}
+	// Files without findings only count as scanned
+	for path := range done {
+		if !seen[path] {
+			s.Report.AddResult(models.ScanResult{FilePath: path})
+		}
+	}
+	return nil
+}
+
 // Wait blocks until scanning is complete
 func (s *Scanner) Wait() {
 	s.wg.Wait()      // Wait for all workers to finish
 	close(s.results) // correct place to close results
 	<-s.done         // Wait for result processor to finish
 }
+
+// Stop cancels the scan: no further files are queued, files already being
+// scanned get grace to finish and are aborted afterwards. The results so far
+// are still processed and the scan is recorded as cancelled. Stop may be
+// called again with a shorter grace, e.g. 0 to abort at once.
+func (s *Scanner) Stop(grace time.Duration) {
+	s.cancelled.Store(true)
+	s.stopWalk()
+	if grace <= 0 {
+		s.cancel()
+		return
+	}
+	time.AfterFunc(grace, s.cancel)
+}
+
+// Cancelled reports whether Stop was called
+func (s *Scanner) Cancelled() bool {
+	return s.cancelled.Load()
+}
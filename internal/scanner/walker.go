@@ -1,47 +1,240 @@
 package scanner
 
 import (
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/digimosa/ai-gdpr-scan/internal/ignore"
 	"github.com/digimosa/ai-gdpr-scan/internal/models"
 )
 
+// fastModeMaxSize is the size limit of FastMode
+const fastModeMaxSize = 1024 * 1024
+
+// walk holds the rules and symlink state of one walk
+type walk struct {
+	include *ignore.Rules // Empty when every file is included
+	exclude *ignore.Rules // Applied after the ignore files, which cannot re-include its matches
+	maxSize int64
+	visited map[string]bool // FollowSymlinks: real paths of the walked directories
+	links   []link          // FollowSymlinks: symlinked directories, walked last
+}
+
+// link is a symlinked directory. Links are walked after the directories
+// themselves, so a target inside the tree is scanned under its own path.
+type link struct {
+	path  string
+	rules []*ignore.Rules
+}
+
 func (s *Scanner) walkFiles() {
 	defer close(s.jobs)
 
-	err := filepath.WalkDir(s.cfg.RootPath, func(path string, d os.DirEntry, err error) error {
+	root := s.cfg.RootPath
+	w := &walk{
+		include: ignore.New(root, s.cfg.Include),
+		exclude: ignore.New(root, s.cfg.Exclude),
+		maxSize: s.cfg.MaxSize,
+		visited: make(map[string]bool),
+	}
+	if s.cfg.FastMode && (w.maxSize == 0 || w.maxSize > fastModeMaxSize) {
+		w.maxSize = fastModeMaxSize
+	}
+
+	info, err := os.Stat(root)
+	if err != nil {
+		log.Printf("Error walking directory: %v", err)
+		return
+	}
+	if !info.IsDir() {
+		s.walkFile(w, root, info)
+		return
+	}
+	s.walkDir(w, root, nil)
+
+	// Walking a link may queue further links
+	for i := 0; i < len(w.links) && s.walkCtx.Err() == nil; i++ {
+		s.walkLink(w, w.links[i])
+	}
+}
+
+// walkLink walks a symlinked directory unless its target was walked already
+// or contains the link itself, which would make the walk loop
+func (s *Scanner) walkLink(w *walk, l link) {
+	real, err := filepath.EvalSymlinks(l.path)
+	if err != nil {
+		log.Printf("Error accessing path %s: %v", l.path, err)
+		s.skip(l.path, models.SkipAccessError)
+		return
+	}
+	parent, err := filepath.EvalSymlinks(filepath.Dir(l.path))
+	sep := string(filepath.Separator)
+	switch {
+	case err == nil && strings.HasPrefix(parent+sep, strings.TrimSuffix(real, sep)+sep):
+		s.skip(l.path, models.SkipSymlinkLoop)
+	case w.visited[real]:
+		s.skip(l.path, models.SkipSymlinkTwice)
+	default:
+		s.walkDir(w, l.path, l.rules)
+	}
+}
+
+// walkDir walks the entries of dir in lexical order. rules are the ignore
+// files of the parent directories, outermost first.
+func (s *Scanner) walkDir(w *walk, dir string, rules []*ignore.Rules) {
+	if s.cfg.FollowSymlinks {
+		if real, err := filepath.EvalSymlinks(dir); err == nil {
+			w.visited[real] = true
+		}
+	}
+
+	if s.cfg.IgnoreFile != "" {
+		r, err := ignore.Load(dir, s.cfg.IgnoreFile)
 		if err != nil {
+			log.Printf("Error reading %s in %s: %v", s.cfg.IgnoreFile, dir, err)
+		}
+		if !r.Empty() {
+			rules = append(rules[:len(rules):len(rules)], r)
+		}
+	}
+
+	// ReadDir returns the entries read before an error
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		log.Printf("Error accessing path %s: %v", dir, err)
+		s.skip(dir, models.SkipAccessError)
+	}
+	for _, d := range entries {
+		if s.walkCtx.Err() != nil {
+			return
+		}
+		s.walkEntry(w, filepath.Join(dir, d.Name()), d, rules)
+	}
+}
+
+func (s *Scanner) walkEntry(w *walk, path string, d fs.DirEntry, rules []*ignore.Rules) {
+	if d.Name() == s.cfg.IgnoreFile {
+		return
+	}
+	if s.cfg.SkipHidden && strings.HasPrefix(d.Name(), ".") {
+		s.skip(path, models.SkipHidden)
+		return
+	}
+
+	isDir := d.IsDir()
+	isLink := d.Type()&fs.ModeSymlink != 0
+	var info fs.FileInfo
+	if isLink {
+		var err error
+		if info, err = os.Stat(path); err != nil {
 			log.Printf("Error accessing path %s: %v", path, err)
-			return nil // Continue walking
+			s.skip(path, models.SkipAccessError)
+			return
 		}
+		isDir = info.IsDir()
+		// Symlinked files are always scanned, directories only on request
+		if isDir && !s.cfg.FollowSymlinks {
+			s.skip(path, models.SkipSymlink)
+			return
+		}
+	}
 
-		if !d.IsDir() {
-			ext := strings.ToLower(filepath.Ext(path))
-			if !s.scannerFactory.IsSupported(ext) {
-				return nil
-			}
+	if reason := w.excluded(path, isDir, rules); reason != "" {
+		s.skip(path, reason)
+		return
+	}
 
-			// Fast Mode Check
-			if s.cfg.FastMode {
-				info, err := d.Info()
-				if err == nil && info.Size() > 1024*1024 { // Skip > 1MB
-					return nil
-				}
-			}
+	if isDir {
+		if isLink {
+			w.links = append(w.links, link{path: path, rules: rules})
+			return
+		}
+		s.walkDir(w, path, rules)
+		return
+	}
+
+	if info == nil {
+		var err error
+		if info, err = d.Info(); err != nil {
+			log.Printf("Error accessing path %s: %v", path, err)
+			s.skip(path, models.SkipAccessError)
+			return
+		}
+	}
+	s.walkFile(w, path, info)
+}
+
+// walkFile queues a file that passes the include list and the limits
+func (s *Scanner) walkFile(w *walk, path string, info fs.FileInfo) {
+	if !w.include.Empty() && !w.include.MatchAny(path) {
+		s.skip(path, models.SkipNotIncluded)
+		return
+	}
 
-			select {
-			case <-s.ctx.Done():
-				return filepath.SkipAll
-			case s.jobs <- models.Job{FilePath: path}:
+	ext := strings.ToLower(filepath.Ext(path))
+	if !s.scannerFactory.IsSupported(ext) {
+		s.skip(path, models.SkipUnsupported)
+		return
+	}
+
+	// Stored before the scan was interrupted
+	if s.completed[path] {
+		return
+	}
+
+	if reason := s.outsideLimits(w, info); reason != "" {
+		s.skip(path, reason)
+		return
+	}
+
+	select {
+	case <-s.walkCtx.Done():
+	case s.jobs <- models.Job{FilePath: path}:
+	}
+}
+
+// excluded returns why the ignore files or exclude patterns skip path, or
+// "" if they don't. The last matching pattern decides.
+func (w *walk) excluded(path string, isDir bool, rules []*ignore.Rules) string {
+	reason := ""
+	for _, r := range rules {
+		if ignored, matched := r.Match(path, isDir); matched {
+			reason = ""
+			if ignored {
+				reason = models.SkipIgnoreFile
 			}
 		}
-		return nil
-	})
+	}
+	if ignored, _ := w.exclude.Match(path, isDir); ignored {
+		reason = models.SkipExcluded
+	}
+	return reason
+}
 
-	if err != nil {
-		log.Printf("Error walking directory: %v", err)
+// outsideLimits returns which size or age limit a file violates, or ""
+func (s *Scanner) outsideLimits(w *walk, info fs.FileInfo) string {
+	age := time.Since(info.ModTime())
+	switch {
+	case w.maxSize > 0 && info.Size() > w.maxSize:
+		return models.SkipTooLarge
+	case s.cfg.MinSize > 0 && info.Size() < s.cfg.MinSize:
+		return models.SkipTooSmall
+	case s.cfg.MaxAge > 0 && age > s.cfg.MaxAge:
+		return models.SkipTooOld
+	case s.cfg.MinAge > 0 && age < s.cfg.MinAge:
+		return models.SkipTooNew
+	}
+	return ""
+}
+
+// skip counts a path the walker leaves out
+func (s *Scanner) skip(path, reason string) {
+	s.Report.AddSkipped(reason)
+	if s.cfg.Verbose {
+		log.Printf("[SKIP] %s: %s", path, reason)
 	}
 }
@@ -0,0 +1,68 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/models"
+)
+
+// walkedFiles returns the files the walker queues below root, relative to it
+func walkedFiles(t *testing.T, s *Scanner, root string) []string {
+	t.Helper()
+	go s.walkFiles()
+	var files []string
+	for job := range s.jobs {
+		rel, _ := filepath.Rel(root, job.FilePath)
+		files = append(files, rel)
+	}
+	sort.Strings(files)
+	return files
+}
+
+func TestWalkSymlinks(t *testing.T) {
+	root := t.TempDir()
+	target := t.TempDir()
+	if err := os.WriteFile(filepath.Join(target, "linked.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "plain.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for name, to := range map[string]string{
+		"file.txt": filepath.Join(target, "linked.txt"),
+		"dir":      target,
+		"gone.txt": filepath.Join(target, "missing.txt"),
+	} {
+		if err := os.Symlink(to, filepath.Join(root, name)); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+	}
+
+	tests := []struct {
+		follow      bool
+		want        string
+		skippedDirs int64
+	}{
+		{false, "file.txt,plain.txt", 1},
+		{true, "dir/linked.txt,file.txt,plain.txt", 0},
+	}
+	for _, tt := range tests {
+		s, _, cfg := newTestScanner(t, nil)
+		cfg.RootPath, cfg.FollowSymlinks = root, tt.follow
+
+		if got := strings.Join(walkedFiles(t, s, root), ","); got != tt.want {
+			t.Errorf("follow %v: walked %s, want %s", tt.follow, got, tt.want)
+		}
+		skipped := s.Report.Summary.Skipped
+		if skipped[models.SkipAccessError] != 1 {
+			t.Errorf("follow %v: skipped %v, want the broken link as an access error", tt.follow, skipped)
+		}
+		if skipped[models.SkipSymlink] != tt.skippedDirs {
+			t.Errorf("follow %v: %d symlinked directories skipped, want %d", tt.follow, skipped[models.SkipSymlink], tt.skippedDirs)
+		}
+	}
+}
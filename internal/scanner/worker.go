@@ -1,15 +1,25 @@
 package scanner
 
+import (
+	"context"
+	"errors"
+)
+
 func (s *Scanner) worker(id int) {
 	defer s.wg.Done()
 
 	for job := range s.jobs {
-		select {
-		case <-s.ctx.Done():
-			return
-		default:
-			result := s.scanFile(job.FilePath)
-			s.results <- result
+		// After Stop the queue is drained without scanning, so the walker
+		// is never blocked on a full channel
+		if s.walkCtx.Err() != nil {
+			continue
+		}
+		result := s.scanFile(job.FilePath, nil)
+		// A file aborted during extraction or AI analysis is neither
+		// reported nor checkpointed, so it is scanned again on -resume
+		if errors.Is(result.Error, context.Canceled) {
+			continue
 		}
+		s.results <- result
 	}
 }
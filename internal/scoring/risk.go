@@ -0,0 +1,76 @@
+package scoring
+
+import (
+	"math"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/extractor/detectors"
+	"github.com/digimosa/ai-gdpr-scan/internal/models"
+)
+
+// typeWeights is the risk contribution of a single finding at confidence 1.0
+var typeWeights = map[models.FindingType]float64{
+	models.TypeIBAN:       8,
+	models.TypeCreditCard: 12,
+	models.TypeEmail:      2,
+	models.TypePhone:      2,
+	models.TypeName:       2,
+	models.TypeIdentity:   1,
+	models.TypeFinancial:  1,
+	models.TypeID:         6,
+	models.TypeSensitive:  12,
+	models.TypeCredential: 15,
+}
+
+// subtypeWeights override typeWeights where the subtype changes the risk
+var subtypeWeights = map[string]float64{
+	detectors.PhoneMobile:        4,
+	detectors.PhoneFixedOrMobile: 3,
+	detectors.PhoneLandline:      2,
+	detectors.PhoneTollFree:      0.5,
+	detectors.PhoneService:       0.5,
+	detectors.Article9Health:     15,
+	detectors.Article9Genetic:    15,
+	detectors.Article9Biometric:  15,
+}
+
+// credentialMultiplier applies when credentials appear next to personal data:
+// the file then holds both the data and the keys to more of it
+const credentialMultiplier = 1.5
+
+// riskSaturation is the weighted finding sum at which a file reaches ~63 points
+const riskSaturation = 40.0
+
+// RiskScore rates a file from 0 to 100 based on its findings
+func RiskScore(findings []models.Finding) float64 {
+	sum := 0.0
+	hasCredential := false
+	hasPersonalData := false
+
+	for _, f := range findings {
+		if f.Unverified {
+			continue // The value does not occur in the file
+		}
+		t := models.FindingType(f.Type)
+		weight, ok := subtypeWeights[f.Subtype]
+		if !ok || (t != models.TypePhone && t != models.TypeSensitive) {
+			weight = typeWeights[t]
+		}
+		if weight == 0 {
+			weight = 1 // Unknown types from the AI still count
+		}
+		sum += weight * f.Confidence
+
+		if t == models.TypeCredential {
+			hasCredential = true
+		} else {
+			hasPersonalData = true
+		}
+	}
+
+	if hasCredential && hasPersonalData {
+		sum *= credentialMultiplier
+	}
+
+	score := 100 * (1 - math.Exp(-sum/riskSaturation))
+	return math.Round(score*10) / 10
+}
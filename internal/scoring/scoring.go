@@ -0,0 +1,167 @@
+// Package scoring sits between detection and AI analysis. It turns keyword
+// matches into context signals for nearby values and decides which matches
+// are worth reporting.
+package scoring
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/config"
+	"github.com/digimosa/ai-gdpr-scan/internal/models"
+)
+
+// relatedValues lists the value types a keyword category vouches for
+var relatedValues = map[models.FindingType][]models.FindingType{
+	models.TypeIdentity:  {models.TypeName, models.TypeEmail, models.TypePhone},
+	models.TypeFinancial: {models.TypeIBAN, models.TypeCreditCard, models.TypeName},
+	models.TypeID:        {models.TypeName},
+	models.TypeSensitive: {models.TypeName, models.TypeSensitive},
+}
+
+// baseConfidence is used for values whose detector does not score
+const baseConfidence = 0.5
+
+// keywordConfidence is the confidence of a keyword reported on its own
+const keywordConfidence = 0.3
+
+// Scorer raises the confidence of values that sit close to a matching label
+type Scorer struct {
+	Window          int64   // Max distance in bytes between label and value
+	Boost           float64 // Share of the remaining confidence a perfectly placed label adds
+	KeywordFindings map[models.FindingType]bool
+}
+
+func NewScorer(cfg *config.Config) *Scorer {
+	s := &Scorer{
+		Window:          int64(cfg.KeywordWindow),
+		Boost:           cfg.KeywordBoost,
+		KeywordFindings: make(map[models.FindingType]bool),
+	}
+	for _, t := range cfg.KeywordFindings {
+		s.KeywordFindings[models.FindingType(strings.TrimSpace(t))] = true
+	}
+	return s
+}
+
+// Score deduplicates matches, applies keyword proximity and drops keywords
+// that are not configured as findings. The result is ordered by offset.
+func (s *Scorer) Score(matches []models.Match) []models.Match {
+	matches = dedupe(matches)
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Offset < matches[j].Offset })
+
+	var keywords []models.Match
+	for _, m := range matches {
+		if m.Keyword {
+			keywords = append(keywords, m)
+		}
+	}
+
+	var out []models.Match
+	for _, m := range matches {
+		if m.Keyword {
+			if s.KeywordFindings[m.Type] {
+				m.Confidence = keywordConfidence
+				out = append(out, m)
+			}
+			continue
+		}
+
+		proximity, labels := s.nearestLabels(m, keywords)
+		if proximity > 0 {
+			base := m.Confidence
+			if base == 0 {
+				base = baseConfidence
+			}
+			m.Confidence = base + (1-base)*s.Boost*proximity
+			if m.Confidence > 0.99 {
+				m.Confidence = 0.99
+			}
+			m.Labels = labels
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// nearestLabels returns the best proximity (0..1) of a related keyword and the labels within the window.
+// keywords must be sorted by offset.
+func (s *Scorer) nearestLabels(value models.Match, keywords []models.Match) (float64, []string) {
+	if s.Window <= 0 || len(keywords) == 0 {
+		return 0, nil
+	}
+
+	valueEnd := value.Offset + int64(len(value.Value))
+	first := sort.Search(len(keywords), func(i int) bool {
+		return keywords[i].Offset >= value.Offset-s.Window
+	})
+
+	best := 0.0
+	var labels []string
+	seen := make(map[string]bool)
+
+	for _, kw := range keywords[first:] {
+		if kw.Offset > valueEnd+s.Window {
+			break
+		}
+		if !isRelated(kw.Type, value.Type) {
+			continue
+		}
+
+		kwEnd := kw.Offset + int64(len(kw.Value))
+		var dist int64
+		switch {
+		case kwEnd <= value.Offset:
+			// Label in front of the value: "Kontonummer: DE89..."
+			dist = value.Offset - kwEnd
+		case kw.Offset >= valueEnd:
+			// Labels after the value are a weaker signal
+			dist = 2 * (kw.Offset - valueEnd)
+		default:
+			// Keyword inside the value itself ("Name" in "Namensliste") says nothing
+			continue
+		}
+		if dist > s.Window {
+			continue
+		}
+
+		if p := 1 - float64(dist)/float64(s.Window); p > best {
+			best = p
+		}
+		label := strings.ToLower(kw.Value)
+		if !seen[label] {
+			seen[label] = true
+			labels = append(labels, kw.Value)
+		}
+	}
+	return best, labels
+}
+
+func isRelated(keyword, value models.FindingType) bool {
+	for _, t := range relatedValues[keyword] {
+		if t == value {
+			return true
+		}
+	}
+	return false
+}
+
+// dedupe drops identical matches, e.g. from the overlap between text chunks
+func dedupe(matches []models.Match) []models.Match {
+	type key struct {
+		t      models.FindingType
+		value  string
+		offset int64
+	}
+	seen := make(map[key]bool, len(matches))
+	out := matches[:0]
+	for _, m := range matches {
+		k := key{m.Type, m.Value, m.Offset}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		out = append(out, m)
+	}
+	return out
+}
@@ -1,18 +1,25 @@
 package server
 
 import (
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/digimosa/ai-gdpr-scan/internal/auditlog"
 	"github.com/digimosa/ai-gdpr-scan/internal/config"
 	"github.com/digimosa/ai-gdpr-scan/internal/models"
 	"github.com/digimosa/ai-gdpr-scan/internal/reporting"
 	"github.com/digimosa/ai-gdpr-scan/internal/scanner"
+	"github.com/digimosa/ai-gdpr-scan/internal/scoring"
 	"github.com/digimosa/ai-gdpr-scan/internal/storage"
 	"github.com/digimosa/ai-gdpr-scan/internal/templates"
 	"github.com/digimosa/ai-gdpr-scan/internal/whitelist"
@@ -30,6 +37,7 @@ type Server struct {
 	whitelist *whitelist.Whitelist
 	mu        sync.RWMutex
 	scanning  bool
+	current   *scanner.Scanner // The running web-triggered scan
 	status    string
 	tmpl      *html_template.Template
 }
@@ -47,10 +55,11 @@ func NewServer(cfg *config.Config, report *reporting.Report, wl *whitelist.White
 
 func (s *Server) Start(addr string) error {
 	http.HandleFunc("/", s.handleDashboard)
-	http.HandleFunc("/api/scans", s.handleListScans) // JSON list of scans
-	http.HandleFunc("/api/scans/", s.handleGetScan)  // JSON detail of a scan
-	http.HandleFunc("/scan", s.handleScan)           // Trigger new scan
-	http.HandleFunc("/logs/ai", s.handleAILogs)      // Stream/Get AI logs
+	http.HandleFunc("/api/scans", s.handleListScans)    // JSON list of scans
+	http.HandleFunc("/api/scans/", s.handleGetScan)     // JSON detail of a scan
+	http.HandleFunc("/scan", s.handleScan)              // Trigger new scan
+	http.HandleFunc("/scan/cancel", s.handleCancelScan) // Stop the running scan
+	http.HandleFunc("/api/logs/ai", s.handleAILogs)     // Paginated AI audit log
 	http.HandleFunc("/whitelist", s.handleWhitelist)
 	http.HandleFunc("/feedback", s.handleFeedback) // Feedback API
 
@@ -79,15 +88,76 @@ func (s *Server) handleGetScan(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(scan)
 }
 
+// handleAILogs serves a page of the AI audit log as JSON, newest first.
+// Query parameters: page, per_page, file (part of the path), model, kind,
+// status, request_id and since (RFC 3339).
 func (s *Server) handleAILogs(w http.ResponseWriter, r *http.Request) {
-	// Read last N lines from log file
-	content, err := os.ReadFile("ai_debug.log")
-	if err != nil {
-		http.Error(w, "Log file not found", http.StatusNotFound)
+	if !s.authorized(r) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	if s.cfg.AIAuditLog == "" {
+		http.Error(w, "AI audit log is disabled", http.StatusNotFound)
 		return
 	}
-	w.Header().Set("Content-Type", "text/plain")
-	w.Write(content)
+
+	params := r.URL.Query()
+	page, perPage := 1, 50
+	if n, err := strconv.Atoi(params.Get("page")); err == nil && n > 0 {
+		page = n
+	}
+	if n, err := strconv.Atoi(params.Get("per_page")); err == nil && n > 0 {
+		perPage = n
+	}
+	if perPage > maxLogsPerPage {
+		perPage = maxLogsPerPage
+	}
+
+	query := auditlog.Query{
+		RequestID: params.Get("request_id"),
+		File:      params.Get("file"),
+		Model:     params.Get("model"),
+		Kind:      params.Get("kind"),
+		Status:    params.Get("status"),
+		Offset:    (page - 1) * perPage,
+		Limit:     perPage,
+	}
+	if since := params.Get("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			http.Error(w, "since must be an RFC 3339 time", http.StatusBadRequest)
+			return
+		}
+		query.Since = t
+	}
+
+	result, err := auditlog.Read(s.cfg.AIAuditLog, s.cfg.AIAuditBackups, query)
+	if errors.Is(err, os.ErrNotExist) {
+		result = &auditlog.Page{Entries: []auditlog.Entry{}, Offset: query.Offset, Limit: query.Limit}
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// maxLogsPerPage caps the per_page parameter of the audit log endpoint
+const maxLogsPerPage = 500
+
+// authorized reports whether r may read the audit log: with an admin token
+// it must be sent as a bearer token, without one only loopback clients pass
+func (s *Server) authorized(r *http.Request) bool {
+	if s.cfg.AdminToken != "" {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		return subtle.ConstantTimeCompare([]byte(token), []byte(s.cfg.AdminToken)) == 1
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
 }
 
 func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
@@ -148,18 +218,28 @@ func (s *Server) renderHistory(w http.ResponseWriter) {
 		}
 	}
 
+	// Document categories per scan, for the category filter
+	scanCategories, err := storage.ScanCategories()
+	if err != nil {
+		log.Printf("[ERROR] failed to load document categories: %v", err)
+	}
+
 	data := struct {
-		Scans         []storage.ScanModel
-		TotalScans    int
-		TotalFindings int64
-		TotalPIIFiles int64
-		SuccessRate   int
+		Scans          []storage.ScanModel
+		TotalScans     int
+		TotalFindings  int64
+		TotalPIIFiles  int64
+		SuccessRate    int
+		Categories     []string
+		ScanCategories map[uint]map[string]int64
 	}{
-		Scans:         scans,
-		TotalScans:    len(scans),
-		TotalFindings: totalFindings,
-		TotalPIIFiles: totalPIIFiles,
-		SuccessRate:   0,
+		Scans:          scans,
+		TotalScans:     len(scans),
+		TotalFindings:  totalFindings,
+		TotalPIIFiles:  totalPIIFiles,
+		SuccessRate:    0,
+		Categories:     models.Categories,
+		ScanCategories: scanCategories,
 	}
 
 	if len(scans) > 0 {
@@ -185,6 +265,7 @@ func (s *Server) handleScan(w http.ResponseWriter, r *http.Request) {
 	}
 
 	fastMode := r.FormValue("fast_mode") == "on"
+	incremental := r.FormValue("incremental") == "on"
 	aiEnabled := r.FormValue("ai_enabled") == "on"
 
 	s.mu.Lock()
@@ -196,27 +277,60 @@ func (s *Server) handleScan(w http.ResponseWriter, r *http.Request) {
 	s.scanning = true
 	s.mu.Unlock()
 
+	// An interrupted scan continues with its own root path
+	var resume *storage.ScanModel
+	if id := r.FormValue("resume"); id != "" {
+		scanID, err := strconv.ParseUint(id, 10, 64)
+		if err == nil {
+			resume, err = storage.ResumeScan(uint(scanID))
+		}
+		if err != nil {
+			s.mu.Lock()
+			s.scanning = false
+			s.mu.Unlock()
+			http.Error(w, fmt.Sprintf("Cannot resume scan: %v", err), http.StatusBadRequest)
+			return
+		}
+		path = resume.RootPath
+	}
+
 	// Update config
 	s.cfg.RootPath = path
 	s.cfg.FastMode = fastMode
+	s.cfg.Incremental = incremental
 	s.cfg.DisableAI = !aiEnabled
 
 	go func() {
 		defer func() {
 			s.mu.Lock()
 			s.scanning = false
+			s.current = nil
 			s.mu.Unlock()
 		}()
 
 		log.Printf("Starting web-triggered scan on: %s", path)
 		scanner := scanner.NewScanner(s.cfg)
 		scanner.Whitelist = s.whitelist // Share whitelist
+		if resume != nil {
+			if err := scanner.Resume(resume); err != nil {
+				log.Printf("[ERROR] could not resume scan %d: %v", resume.ID, err)
+				return
+			}
+			log.Printf("Resuming scan %d, %d files already scanned", resume.ID, scanner.Report.Summary.TotalFilesScanned)
+		}
+		s.mu.Lock()
+		s.current = scanner
+		s.mu.Unlock()
 		scanner.Start()
 		scanner.Wait()
 
 		s.mu.Lock()
 		s.report = scanner.Report
 		s.mu.Unlock()
+		if scanner.Cancelled() {
+			log.Println("Web-triggered scan cancelled")
+			return
+		}
 		log.Println("Web-triggered scan finished")
 	}()
 
@@ -224,6 +338,27 @@ func (s *Server) handleScan(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
 
+// handleCancelScan stops the running web-triggered scan. Files being scanned
+// get the configured grace to finish; the partial results are kept.
+func (s *Server) handleCancelScan(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.mu.RLock()
+	current := s.current
+	s.mu.RUnlock()
+	if current == nil {
+		http.Error(w, "No scan in progress", http.StatusConflict)
+		return
+	}
+
+	log.Printf("Cancelling web-triggered scan, waiting up to %s for running files", s.cfg.ShutdownGrace)
+	current.Stop(s.cfg.ShutdownGrace)
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
 func (s *Server) handleWhitelist(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -291,31 +426,29 @@ func convertToReport(scan *storage.ScanModel) *reporting.Report {
 	report.Summary.StartTime = scan.StartTime
 	report.Summary.EndTime = scan.EndTime
 	report.Summary.ScanDuration = scan.Duration
+	report.Summary.Cancelled = scan.Status == "Cancelled"
+
+	for _, res := range scan.Results() {
+		if len(res.Findings) == 0 {
+			continue
+		}
+		res.RiskScore = scoring.RiskScore(res.Findings)
+		report.AddResult(res)
+	}
+	// The stored totals include the files without findings
 	report.Summary.TotalFilesScanned = scan.TotalFiles
 	report.Summary.TotalFilesWithPII = scan.PIIFiles
 	report.Summary.TotalPIIFound = scan.TotalFindings
 
-	// Convert Findings
-	grouped := make(map[string][]models.Finding)
-
-	for _, f := range scan.Findings {
-		finding := models.Finding{
-			ID:         f.ID,
-			Type:       f.Type,
-			Snippet:    f.Value,
-			Confidence: f.Confidence,
-			Offset:     0,
-			Context:    f.Reason,
-			Feedback:   f.Feedback,
+	// Classified files without findings count as well
+	report.Summary.Categories = nil
+	for _, f := range scan.Files {
+		if f.Category != "" {
+			if report.Summary.Categories == nil {
+				report.Summary.Categories = make(map[string]int64)
+			}
+			report.Summary.Categories[f.Category]++
 		}
-		grouped[f.FilePath] = append(grouped[f.FilePath], finding)
-	}
-
-	for path, findings := range grouped {
-		report.AddResult(models.ScanResult{
-			FilePath: path,
-			Findings: findings,
-		})
 	}
 
 	return report
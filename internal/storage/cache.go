@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// AIVerdictModel is a cached model answer for one prompt
+type AIVerdictModel struct {
+	Key       string    `gorm:"primaryKey" json:"key"` // hash(model, prompt version, context)
+	Verdict   string    `json:"verdict"`               // Validated findings as JSON
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+// VerdictCache stores AI verdicts in the scan database for TTL
+type VerdictCache struct {
+	TTL time.Duration
+}
+
+// NewVerdictCache opens the verdict cache and removes expired entries
+func NewVerdictCache(ttl time.Duration) *VerdictCache {
+	DB.Where("created_at < ?", time.Now().Add(-ttl)).Delete(&AIVerdictModel{})
+	return &VerdictCache{TTL: ttl}
+}
+
+// Get returns the cached verdict for key unless it has expired
+func (c *VerdictCache) Get(key string) (string, bool) {
+	var v AIVerdictModel
+	if err := DB.Where("key = ? AND created_at >= ?", key, time.Now().Add(-c.TTL)).Limit(1).Find(&v).Error; err != nil || v.Key == "" {
+		return "", false
+	}
+	return v.Verdict, true
+}
+
+// Put stores or refreshes the verdict for key
+func (c *VerdictCache) Put(key, verdict string) {
+	v := AIVerdictModel{Key: key, Verdict: verdict, CreatedAt: time.Now()}
+	DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(&v)
+}
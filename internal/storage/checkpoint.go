@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/models"
+	"gorm.io/gorm"
+)
+
+// CheckpointModel marks a file whose result a scan has stored, so an
+// interrupted scan can be resumed without scanning it again
+type CheckpointModel struct {
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	ScanID   uint   `gorm:"uniqueIndex:idx_checkpoint_file" json:"scan_id"`
+	FilePath string `gorm:"uniqueIndex:idx_checkpoint_file" json:"file_path"`
+}
+
+// SaveResult stores the findings and file verdicts of a scan result and
+// checkpoints the file in one transaction: after a crash a file is either
+// stored completely or scanned again on resume
+func SaveResult(scanID uint, res models.ScanResult) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		for _, finding := range res.Findings {
+			f := newFindingModel(scanID, res.FilePath, finding)
+			if err := tx.Create(&f).Error; err != nil {
+				return err
+			}
+		}
+		if res.Category != "" || len(res.Findings) > 0 || res.Mismatch {
+			f := newFileModel(scanID, res)
+			if err := tx.Create(&f).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(&CheckpointModel{ScanID: scanID, FilePath: res.FilePath}).Error
+	})
+}
+
+// CheckpointedFiles returns the files a scan has already stored. Files with
+// findings of scans from before checkpointing count as well.
+func CheckpointedFiles(scan *ScanModel) (map[string]bool, error) {
+	var paths []string
+	if err := DB.Model(&CheckpointModel{}).Where("scan_id = ?", scan.ID).Pluck("file_path", &paths).Error; err != nil {
+		return nil, err
+	}
+	done := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		done[p] = true
+	}
+	for _, f := range scan.Findings {
+		done[f.FilePath] = true
+	}
+	for _, f := range scan.Files {
+		done[f.FilePath] = true
+	}
+	return done, nil
+}
+
+// InterruptedScans returns the scans still marked "Running", newest first.
+// Outside of a running scan these are scans whose process died.
+func InterruptedScans() ([]ScanModel, error) {
+	var scans []ScanModel
+	err := DB.Where("status = ?", "Running").Order("start_time desc").Find(&scans).Error
+	return scans, err
+}
+
+// CountCheckpoints returns the number of files a scan has stored
+func CountCheckpoints(scanID uint) (int64, error) {
+	var n int64
+	err := DB.Model(&CheckpointModel{}).Where("scan_id = ?", scanID).Count(&n).Error
+	return n, err
+}
+
+// ResumeScan loads an unfinished scan and marks it running again.
+// Completed scans cannot be resumed.
+func ResumeScan(id uint) (*ScanModel, error) {
+	scan, err := GetScanByID(fmt.Sprintf("%d", id))
+	if err != nil {
+		return nil, fmt.Errorf("scan %d not found", id)
+	}
+	if scan.Status == "Completed" {
+		return nil, fmt.Errorf("scan %d is %s and cannot be resumed", id, scan.Status)
+	}
+	scan.Status = "Running"
+	if err := DB.Model(scan).Update("status", scan.Status).Error; err != nil {
+		return nil, err
+	}
+	return scan, nil
+}
@@ -1,8 +1,12 @@
 package storage
 
 import (
+	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/digimosa/ai-gdpr-scan/internal/models"
+
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -10,7 +14,7 @@ import (
 type ScanModel struct {
 	ID            uint           `gorm:"primaryKey" json:"id"`
 	RootPath      string         `json:"root_path"`
-	Status        string         `json:"status"` // "Running", "Completed", "Failed"
+	Status        string         `json:"status"` // "Running", "Completed", "Cancelled", "Failed"
 	StartTime     time.Time      `json:"start_time"`
 	EndTime       time.Time      `json:"end_time"`
 	Duration      time.Duration  `json:"duration"`
@@ -18,16 +22,38 @@ type ScanModel struct {
 	PIIFiles      int64          `json:"pii_files"`
 	TotalFindings int64          `json:"total_findings"`
 	Findings      []FindingModel `gorm:"foreignKey:ScanID" json:"findings"`
+	Files         []FileModel    `gorm:"foreignKey:ScanID" json:"files"`
+}
+
+// FileModel holds the per-file verdicts of a scan that are not tied to a
+// single finding
+type FileModel struct {
+	ID                 uint    `gorm:"primaryKey" json:"id"`
+	ScanID             uint    `gorm:"index" json:"scan_id"`
+	FilePath           string  `json:"file_path"`
+	Category           string  `gorm:"index" json:"category"` // See models.Categories
+	CategoryConfidence float64 `json:"category_confidence"`
+	CategorySource     string  `json:"category_source"` // "rules", "ai" or "rules+ai"
+	RiskScore          float64 `json:"risk_score"`
+	MIMEType           string  `json:"mime_type"`     // Detected from the content
+	TypeMismatch       bool    `json:"type_mismatch"` // The extension belongs to a different type than the content
 }
 
 type FindingModel struct {
 	ID         uint      `gorm:"primaryKey" json:"id"`
-	ScanID     uint      `json:"scan_id"`
-	FilePath   string    `json:"file_path"`
+	ScanID     uint      `gorm:"index:idx_finding_file" json:"scan_id"`
+	FilePath   string    `gorm:"index:idx_finding_file" json:"file_path"`
 	Type       string    `json:"type"`
-	Value      string    `json:"value"` // Sanitized snippet
+	Subtype    string    `json:"subtype"` // e.g. phone type or Article 9 category
+	Value      string    `json:"value"`   // Sanitized snippet
+	Offset     int64     `json:"offset"`
+	Line       int       `json:"line"`
+	Location   string    `json:"location"` // e.g. "Sheet1!B3"
+	Origin     string    `json:"origin"`   // "regex", "ai" or "ai+regex"
+	Unverified bool      `json:"unverified"`
 	Confidence float64   `json:"confidence"`
 	Reason     string    `json:"reason"`
+	Excerpt    string    `json:"-"`        // Value with surrounding text, see models.Finding
 	Feedback   string    `json:"feedback"` // "Correct" or "Incorrect"
 	CreatedAt  time.Time `json:"created_at"`
 }
@@ -41,7 +67,7 @@ func Init(path string) error {
 	if err != nil {
 		return err
 	}
-	return DB.AutoMigrate(&ScanModel{}, &FindingModel{})
+	return DB.AutoMigrate(&ScanModel{}, &FindingModel{}, &FileModel{}, &AIVerdictModel{}, &EmbeddingModel{}, &CheckpointModel{}, &InventoryModel{})
 }
 
 func CreateScan(rootPath string) (*ScanModel, error) {
@@ -55,42 +81,164 @@ func CreateScan(rootPath string) (*ScanModel, error) {
 }
 
 func CompleteScan(s *ScanModel, totalFiles, piiFiles, totalFindings int64) error {
+	return finishScan(s, "Completed", totalFiles, piiFiles, totalFindings)
+}
+
+// CancelScan records a scan stopped before all files were scanned, with the
+// totals of the files scanned so far
+func CancelScan(s *ScanModel, totalFiles, piiFiles, totalFindings int64) error {
+	return finishScan(s, "Cancelled", totalFiles, piiFiles, totalFindings)
+}
+
+func finishScan(s *ScanModel, status string, totalFiles, piiFiles, totalFindings int64) error {
 	s.EndTime = time.Now()
 	s.Duration = s.EndTime.Sub(s.StartTime)
-	s.Status = "Completed"
+	s.Status = status
 	s.TotalFiles = totalFiles
 	s.PIIFiles = piiFiles
 	s.TotalFindings = totalFindings
 	return DB.Model(s).Select("EndTime", "Duration", "Status", "TotalFiles", "PIIFiles", "TotalFindings").Updates(s).Error
 }
 
-func SaveFinding(scanID uint, path, piiType, value, reason string, confidence float64) error {
-	f := FindingModel{
+func SaveFinding(scanID uint, path string, finding models.Finding) error {
+	f := newFindingModel(scanID, path, finding)
+	// Update counts on scan atomically? Or just aggregate later.
+	// For simplicity, just insert finding
+	return DB.Create(&f).Error
+}
+
+func newFindingModel(scanID uint, path string, finding models.Finding) FindingModel {
+	return FindingModel{
 		ScanID:     scanID,
 		FilePath:   path,
-		Type:       piiType,
-		Value:      value,
-		Reason:     reason,
-		Confidence: confidence,
+		Type:       finding.Type,
+		Subtype:    finding.Subtype,
+		Value:      finding.Snippet,
+		Offset:     finding.Offset,
+		Line:       finding.Line,
+		Location:   finding.Location,
+		Origin:     finding.Origin,
+		Unverified: finding.Unverified,
+		Reason:     finding.Context,
+		Excerpt:    finding.Excerpt,
+		Confidence: finding.Confidence,
+		Feedback:   finding.Feedback, // Set for findings carried forward by incremental scans
 		CreatedAt:  time.Now(),
 	}
-	// Update counts on scan atomically? Or just aggregate later.
-	// For simplicity, just insert finding
+}
+
+// SaveFile records the file level verdicts of a scan result
+func SaveFile(scanID uint, res models.ScanResult) error {
+	f := newFileModel(scanID, res)
 	return DB.Create(&f).Error
 }
 
+func newFileModel(scanID uint, res models.ScanResult) FileModel {
+	return FileModel{
+		ScanID:             scanID,
+		FilePath:           res.FilePath,
+		Category:           res.Category,
+		CategoryConfidence: res.CategoryConfidence,
+		CategorySource:     res.CategorySource,
+		RiskScore:          res.RiskScore,
+		MIMEType:           res.MIMEType,
+		TypeMismatch:       res.Mismatch,
+	}
+}
+
+// ScanCategories returns the number of files per document category for
+// each scan
+func ScanCategories() (map[uint]map[string]int64, error) {
+	var rows []struct {
+		ScanID   uint
+		Category string
+		Files    int64
+	}
+	err := DB.Model(&FileModel{}).
+		Select("scan_id, category, count(*) as files").
+		Where("category <> ''").
+		Group("scan_id, category").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]map[string]int64)
+	for _, r := range rows {
+		if counts[r.ScanID] == nil {
+			counts[r.ScanID] = make(map[string]int64)
+		}
+		counts[r.ScanID][r.Category] = r.Files
+	}
+	return counts, nil
+}
+
 func GetAllScans() ([]ScanModel, error) {
 	var scans []ScanModel
 	err := DB.Order("start_time desc").Find(&scans).Error
 	return scans, err
 }
 
+// Results groups the stored findings and file verdicts of a preloaded scan
+// by file. Files without findings are included when they have a verdict.
+func (s *ScanModel) Results() []models.ScanResult {
+	var results []models.ScanResult
+	index := make(map[string]int)
+	result := func(path string) *models.ScanResult {
+		i, ok := index[path]
+		if !ok {
+			i = len(results)
+			index[path] = i
+			results = append(results, models.ScanResult{FilePath: path, FileType: strings.ToLower(filepath.Ext(path))})
+		}
+		return &results[i]
+	}
+
+	for _, f := range s.Findings {
+		res := result(f.FilePath)
+		res.Findings = append(res.Findings, models.Finding{
+			ID:         f.ID,
+			Type:       f.Type,
+			Subtype:    f.Subtype,
+			Snippet:    f.Value,
+			Confidence: f.Confidence,
+			Offset:     f.Offset,
+			Line:       f.Line,
+			Location:   f.Location,
+			Origin:     f.Origin,
+			Unverified: f.Unverified,
+			Context:    f.Reason,
+			Excerpt:    f.Excerpt,
+			Feedback:   f.Feedback,
+		})
+	}
+	for _, f := range s.Files {
+		res := result(f.FilePath)
+		res.Category = f.Category
+		res.CategoryConfidence = f.CategoryConfidence
+		res.CategorySource = f.CategorySource
+		res.RiskScore = f.RiskScore
+		res.MIMEType = f.MIMEType
+		res.Mismatch = f.TypeMismatch
+	}
+	return results
+}
+
 func GetScanByID(id string) (*ScanModel, error) {
 	var scan ScanModel
-	err := DB.Preload("Findings").First(&scan, "id = ?", id).Error
+	err := DB.Preload("Findings").Preload("Files").First(&scan, "id = ?", id).Error
 	return &scan, err
 }
 
+// LabelledFindings returns the most recent findings reviewers marked
+// "Correct" or "Incorrect", newest first
+func LabelledFindings(limit int) ([]FindingModel, error) {
+	var findings []FindingModel
+	err := DB.Where("feedback IN ?", []string{"Correct", "Incorrect"}).
+		Order("created_at desc").Limit(limit).Find(&findings).Error
+	return findings, err
+}
+
 func UpdateFeedback(id string, feedback string) error {
 	return DB.Model(&FindingModel{}).Where("id = ?", id).Update("feedback", feedback).Error
 }
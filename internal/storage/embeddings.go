@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// EmbeddingModel is a cached embedding of one snippet
+type EmbeddingModel struct {
+	Key       string    `gorm:"primaryKey" json:"key"` // hash(model, text)
+	Vector    []byte    `json:"-"`                     // Little-endian float32 values
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// EmbeddingCache stores snippet embeddings in the scan database. Entries
+// never expire: the key covers everything the vector depends on.
+type EmbeddingCache struct{}
+
+// NewEmbeddingCache opens the embedding cache
+func NewEmbeddingCache() *EmbeddingCache {
+	return &EmbeddingCache{}
+}
+
+// Get returns the cached vector for key
+func (c *EmbeddingCache) Get(key string) ([]float64, bool) {
+	var e EmbeddingModel
+	if err := DB.Where("key = ?", key).Limit(1).Find(&e).Error; err != nil || e.Key == "" {
+		return nil, false
+	}
+	vector := make([]float64, len(e.Vector)/4)
+	for i := range vector {
+		vector[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(e.Vector[i*4:])))
+	}
+	return vector, true
+}
+
+// Put stores the vector for key
+func (c *EmbeddingCache) Put(key string, vector []float64) {
+	data := make([]byte, len(vector)*4)
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(data[i*4:], math.Float32bits(float32(v)))
+	}
+	e := EmbeddingModel{Key: key, Vector: data, CreatedAt: time.Now()}
+	DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(&e)
+}
@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/digimosa/ai-gdpr-scan/internal/models"
+	"gorm.io/gorm/clause"
+)
+
+// InventoryModel is the last known version of a file. Unlike the findings
+// it is kept across scans, so incremental scans can skip unchanged files.
+type InventoryModel struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Path      string    `gorm:"uniqueIndex" json:"path"` // Absolute path
+	FilePath  string    `json:"file_path"`               // Path as recorded by the scan
+	Size      int64     `json:"size"`
+	ModTime   int64     `json:"mod_time"` // Unix nanoseconds
+	Device    uint64    `json:"device"`
+	Inode     uint64    `json:"inode"`
+	Hash      string    `json:"hash"`    // SHA-256 of the content
+	ScanID    uint      `json:"scan_id"` // Last scan that stored the file's result
+	Removed   bool      `gorm:"index" json:"removed"`
+	RemovedAt time.Time `json:"removed_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Fingerprint returns the recorded version of the file
+func (m *InventoryModel) Fingerprint() models.Fingerprint {
+	return models.Fingerprint{
+		Size:    m.Size,
+		ModTime: time.Unix(0, m.ModTime),
+		Device:  m.Device,
+		Inode:   m.Inode,
+		Hash:    m.Hash,
+	}
+}
+
+// GetInventory returns the inventory entry of an absolute path
+func GetInventory(path string) (*InventoryModel, bool) {
+	var m InventoryModel
+	if err := DB.Where("path = ?", path).Limit(1).Find(&m).Error; err != nil || m.ID == 0 {
+		return nil, false
+	}
+	return &m, true
+}
+
+// UpdateInventory records the version of a file whose result scanID stored
+func UpdateInventory(path, filePath string, fp models.Fingerprint, scanID uint) error {
+	m := InventoryModel{
+		Path:      path,
+		FilePath:  filePath,
+		Size:      fp.Size,
+		ModTime:   fp.ModTime.UnixNano(),
+		Device:    fp.Device,
+		Inode:     fp.Inode,
+		Hash:      fp.Hash,
+		ScanID:    scanID,
+		UpdatedAt: time.Now(),
+	}
+	return DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "path"}},
+		DoUpdates: clause.AssignmentColumns([]string{"file_path", "size", "mod_time", "device", "inode", "hash", "scan_id", "removed", "removed_at", "updated_at"}),
+	}).Create(&m).Error
+}
+
+// FileResult returns the stored result of one file of a scan. A file
+// without findings or verdicts yields an empty result.
+func FileResult(scanID uint, path string) (models.ScanResult, error) {
+	scan := ScanModel{ID: scanID}
+	if err := DB.Where("scan_id = ? AND file_path = ?", scanID, path).Find(&scan.Findings).Error; err != nil {
+		return models.ScanResult{}, err
+	}
+	if err := DB.Where("scan_id = ? AND file_path = ?", scanID, path).Find(&scan.Files).Error; err != nil {
+		return models.ScanResult{}, err
+	}
+	if results := scan.Results(); len(results) > 0 {
+		return results[0], nil
+	}
+	return models.ScanResult{FilePath: path}, nil
+}
+
+// MarkRemoved flags the inventory entries below the absolute root that
+// scanID did not store and that no longer exist, and returns their number.
+// Files that only failed to scan keep their entry.
+func MarkRemoved(root string, scanID uint) (int64, error) {
+	// Paths below root sort between root + "/" and root + "0", the next byte
+	prefix := root + string(filepath.Separator)
+	var candidates []InventoryModel
+	err := DB.Where("removed = ? AND scan_id <> ? AND (path = ? OR (path >= ? AND path < ?))",
+		false, scanID, root, prefix, root+string(filepath.Separator+1)).
+		Find(&candidates).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var removed []uint
+	for _, m := range candidates {
+		if _, err := os.Lstat(m.Path); os.IsNotExist(err) {
+			removed = append(removed, m.ID)
+		}
+	}
+	if len(removed) == 0 {
+		return 0, nil
+	}
+	err = DB.Model(&InventoryModel{}).Where("id IN ?", removed).
+		Updates(map[string]interface{}{"removed": true, "removed_at": time.Now()}).Error
+	return int64(len(removed)), err
+}